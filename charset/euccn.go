@@ -0,0 +1,47 @@
+package charset
+
+import "sync"
+
+var (
+	gb2312Once    sync.Once
+	gb2312Indexes map[rune]int
+)
+
+// GB2312 returns EUC-CN double byte code of r
+// both bytes are 0xA1-0xFE, and ok is false if r is not a character of GB 2312
+func GB2312(r rune) (code uint16, ok bool) {
+	gb2312Once.Do(func() {
+		gb2312Indexes = make(map[rune]int, len(gb2312))
+		for i, v := range gb2312 {
+			if v != 0 {
+				gb2312Indexes[rune(v)] = i
+			}
+		}
+	})
+
+	i, ok := gb2312Indexes[r]
+	if !ok {
+		return 0, false
+	}
+
+	// row and cell 1-94 => 0xA1-0xFE
+	first := i/94 + 0xA1
+	second := i%94 + 0xA1
+	return uint16(first<<8 | second), true
+}
+
+// GB2312Rune returns the character of EUC-CN double byte code
+// ok is false if code is not assigned to a character of GB 2312
+func GB2312Rune(code uint16) (r rune, ok bool) {
+	first := int(code >> 8)
+	second := int(code & 0xFF)
+	if first < 0xA1 || first > 0xFE || second < 0xA1 || second > 0xFE {
+		return 0, false
+	}
+
+	v := gb2312[(first-0xA1)*94+second-0xA1]
+	if v == 0 {
+		return 0, false
+	}
+	return rune(v), true
+}
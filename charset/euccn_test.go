@@ -0,0 +1,78 @@
+package charset
+
+import "testing"
+
+func TestGB2312(t *testing.T) {
+	tests := []struct {
+		name   string
+		r      rune
+		want   uint16
+		wantOk bool
+	}{
+		{
+			name:   "ideographic space",
+			r:      '　',
+			want:   0xA1A1,
+			wantOk: true,
+		},
+		{
+			name:   "first hanzi",
+			r:      '啊',
+			want:   0xB0A1,
+			wantOk: true,
+		},
+		{
+			name:   "simplified chinese",
+			r:      '中',
+			want:   0xD6D0,
+			wantOk: true,
+		},
+		{
+			name:   "last hanzi",
+			r:      '齄',
+			want:   0xF7FE,
+			wantOk: true,
+		},
+		{
+			name:   "ascii",
+			r:      'A',
+			wantOk: false,
+		},
+		{
+			name:   "traditional chinese which is not in GB 2312",
+			r:      '體',
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := GB2312(test.r)
+			if ok != test.wantOk {
+				t.Errorf("expected %v, got %v\n", test.wantOk, ok)
+				return
+			}
+			if result != test.want {
+				t.Errorf("expected %#x, got %#x\n", test.want, result)
+			}
+
+			if !ok {
+				return
+			}
+			r, ok := GB2312Rune(result)
+			if !ok || r != test.r {
+				t.Errorf("expected %q, got %q\n", test.r, r)
+			}
+		})
+	}
+}
+
+func TestGB2312Rune(t *testing.T) {
+	// row 10 is not assigned
+	if _, ok := GB2312Rune(0xAAA1); ok {
+		t.Errorf("expected false, got true\n")
+	}
+	if _, ok := GB2312Rune(0x8140); ok {
+		t.Errorf("expected false, got true\n")
+	}
+}
@@ -0,0 +1,118 @@
+package charset
+
+import "testing"
+
+func TestISO8859(t *testing.T) {
+	tests := []struct {
+		name   string
+		part   int
+		r      rune
+		want   byte
+		wantOk bool
+	}{
+		{
+			name:   "ascii",
+			part:   2,
+			r:      'A',
+			want:   'A',
+			wantOk: true,
+		},
+		{
+			name:   "latin-1",
+			part:   1,
+			r:      'é',
+			want:   0xE9,
+			wantOk: true,
+		},
+		{
+			name:   "latin-2",
+			part:   2,
+			r:      'Ł',
+			want:   0xA3,
+			wantOk: true,
+		},
+		{
+			name:   "cyrillic",
+			part:   5,
+			r:      'Ж',
+			want:   0xB6,
+			wantOk: true,
+		},
+		{
+			name:   "euro sign in latin-9",
+			part:   15,
+			r:      '€',
+			want:   0xA4,
+			wantOk: true,
+		},
+		{
+			name:   "character which is not in the part",
+			part:   1,
+			r:      'Ł',
+			wantOk: false,
+		},
+		{
+			name:   "part 12 does not exist",
+			part:   12,
+			r:      'A',
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := ISO8859(test.part, test.r)
+			if ok != test.wantOk {
+				t.Errorf("ok is expected %v, but got %v\n", test.wantOk, ok)
+				return
+			}
+			if result != test.want {
+				t.Errorf("expected %#x, got %#x\n", test.want, result)
+			}
+		})
+	}
+}
+
+func TestISO8859Rune(t *testing.T) {
+	tests := []struct {
+		name   string
+		part   int
+		b      byte
+		want   rune
+		wantOk bool
+	}{
+		{
+			name:   "ascii",
+			part:   7,
+			b:      'z',
+			want:   'z',
+			wantOk: true,
+		},
+		{
+			name:   "greek",
+			part:   7,
+			b:      0xE1,
+			want:   'α',
+			wantOk: true,
+		},
+		{
+			name:   "not assigned",
+			part:   3,
+			b:      0xA5,
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := ISO8859Rune(test.part, test.b)
+			if ok != test.wantOk {
+				t.Errorf("ok is expected %v, but got %v\n", test.wantOk, ok)
+				return
+			}
+			if result != test.want {
+				t.Errorf("expected %c, got %c\n", test.want, result)
+			}
+		})
+	}
+}
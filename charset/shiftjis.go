@@ -0,0 +1,110 @@
+// Package charset converts characters between Unicode and character sets used in QR code
+package charset
+
+import "sync"
+
+var (
+	jis0208Once    sync.Once
+	jis0208Indexes map[rune]int
+)
+
+// ShiftJIS returns Shift_JIS double byte code of r
+// ok is false if r is not a character of JIS X 0208
+func ShiftJIS(r rune) (code uint16, ok bool) {
+	jis0208Once.Do(func() {
+		jis0208Indexes = make(map[rune]int, len(jis0208))
+		for i, v := range jis0208 {
+			if v != 0 {
+				jis0208Indexes[rune(v)] = i
+			}
+		}
+	})
+
+	i, ok := jis0208Indexes[r]
+	if !ok {
+		return 0, false
+	}
+
+	row := i/94 + 1
+	cell := i%94 + 1
+
+	// first byte: row 1-62 => 0x81-0x9F, row 63-94 => 0xE0-0xEF
+	var first int
+	if row <= 62 {
+		first = (row-1)/2 + 0x81
+	} else {
+		first = (row-1)/2 + 0xC1
+	}
+
+	// second byte: odd row => 0x40-0x9E except 0x7F, even row => 0x9F-0xFC
+	var second int
+	if row%2 == 0 {
+		second = cell + 0x9E
+	} else {
+		second = cell + 0x3F
+		if second >= 0x7F {
+			second++
+		}
+	}
+
+	return uint16(first<<8 | second), true
+}
+
+// ShiftJISBytes returns Shift_JIS byte sequence of r
+// ASCII and half-width katakana are single byte, and characters of JIS X 0208 are double bytes
+// ok is false if r is not a character of Shift_JIS
+func ShiftJISBytes(r rune) (b []byte, ok bool) {
+	switch {
+	case 0 <= r && r < 0x80:
+		return []byte{byte(r)}, true
+	case 0xFF61 <= r && r <= 0xFF9F:
+		// half-width katakana: U+FF61-U+FF9F => 0xA1-0xDF
+		return []byte{byte(r - 0xFF61 + 0xA1)}, true
+	}
+
+	code, ok := ShiftJIS(r)
+	if !ok {
+		return nil, false
+	}
+	return []byte{byte(code >> 8), byte(code)}, true
+}
+
+// ShiftJISRune returns the character of Shift_JIS double byte code
+// ok is false if code is not assigned to a character of JIS X 0208
+func ShiftJISRune(code uint16) (r rune, ok bool) {
+	first := int(code >> 8)
+	second := int(code & 0xFF)
+
+	var row int
+	switch {
+	case 0x81 <= first && first <= 0x9F:
+		row = (first-0x81)*2 + 1
+	case 0xE0 <= first && first <= 0xEF:
+		row = (first-0xC1)*2 + 1
+	default:
+		return 0, false
+	}
+
+	var cell int
+	switch {
+	case 0x40 <= second && second <= 0x7E:
+		cell = second - 0x3F
+	case 0x80 <= second && second <= 0x9E:
+		cell = second - 0x40
+	case 0x9F <= second && second <= 0xFC:
+		row++
+		cell = second - 0x9E
+	default:
+		return 0, false
+	}
+
+	if row > 94 {
+		return 0, false
+	}
+
+	v := jis0208[(row-1)*94+cell-1]
+	if v == 0 {
+		return 0, false
+	}
+	return rune(v), true
+}
@@ -0,0 +1,179 @@
+package charset
+
+import "testing"
+
+func TestShiftJIS(t *testing.T) {
+	tests := []struct {
+		name   string
+		r      rune
+		want   uint16
+		wantOk bool
+	}{
+		{
+			name:   "ideographic space",
+			r:      '　',
+			want:   0x8140,
+			wantOk: true,
+		},
+		{
+			name:   "hiragana",
+			r:      'あ',
+			want:   0x82A0,
+			wantOk: true,
+		},
+		{
+			name:   "kanji in level 1",
+			r:      '点',
+			want:   0x935F,
+			wantOk: true,
+		},
+		{
+			name:   "kanji in level 2",
+			r:      '茗',
+			want:   0xE4AA,
+			wantOk: true,
+		},
+		{
+			name:   "last character",
+			r:      '熙',
+			want:   0xEAA4,
+			wantOk: true,
+		},
+		{
+			name:   "ascii",
+			r:      'A',
+			wantOk: false,
+		},
+		{
+			name:   "character which is not in JIS X 0208",
+			r:      '😀',
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := ShiftJIS(test.r)
+			if ok != test.wantOk {
+				t.Errorf("ok is expected %v, but got %v\n", test.wantOk, ok)
+				return
+			}
+			if result != test.want {
+				t.Errorf("expected %#x, got %#x\n", test.want, result)
+			}
+		})
+	}
+}
+
+func TestShiftJISBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		r      rune
+		want   []byte
+		wantOk bool
+	}{
+		{
+			name:   "ascii",
+			r:      'A',
+			want:   []byte{0x41},
+			wantOk: true,
+		},
+		{
+			name:   "half-width katakana",
+			r:      'ｱ',
+			want:   []byte{0xB1},
+			wantOk: true,
+		},
+		{
+			name:   "kanji",
+			r:      '点',
+			want:   []byte{0x93, 0x5F},
+			wantOk: true,
+		},
+		{
+			name:   "character which is not in Shift_JIS",
+			r:      'é',
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := ShiftJISBytes(test.r)
+			if ok != test.wantOk {
+				t.Errorf("ok is expected %v, but got %v\n", test.wantOk, ok)
+				return
+			}
+			if string(result) != string(test.want) {
+				t.Errorf("expected %x, got %x\n", test.want, result)
+			}
+		})
+	}
+}
+
+func TestShiftJISRune(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   uint16
+		want   rune
+		wantOk bool
+	}{
+		{
+			name:   "kanji",
+			code:   0x935F,
+			want:   '点',
+			wantOk: true,
+		},
+		{
+			name:   "odd row and second byte is over 0x80",
+			code:   0x8180,
+			want:   '÷',
+			wantOk: true,
+		},
+		{
+			name:   "not assigned",
+			code:   0x81AD,
+			wantOk: false,
+		},
+		{
+			name:   "invalid second byte",
+			code:   0x817F,
+			wantOk: false,
+		},
+		{
+			name:   "invalid first byte",
+			code:   0xA0A0,
+			wantOk: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := ShiftJISRune(test.code)
+			if ok != test.wantOk {
+				t.Errorf("ok is expected %v, but got %v\n", test.wantOk, ok)
+				return
+			}
+			if result != test.want {
+				t.Errorf("expected %c, got %c\n", test.want, result)
+			}
+		})
+	}
+}
+
+func TestShiftJISRoundTrip(t *testing.T) {
+	for i, v := range jis0208 {
+		if v == 0 {
+			continue
+		}
+		code, ok := ShiftJIS(rune(v))
+		if !ok {
+			t.Errorf("%c cannot be converted at index %d\n", rune(v), i)
+			continue
+		}
+		r, ok := ShiftJISRune(code)
+		if !ok || r != rune(v) {
+			t.Errorf("expected %c, got %c at index %d\n", rune(v), r, i)
+		}
+	}
+}
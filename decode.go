@@ -0,0 +1,767 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ksrnnb/qrcode/bitset"
+	"github.com/ksrnnb/qrcode/charset"
+	"github.com/ksrnnb/qrcode/reedsolomon"
+)
+
+// maxBCHErrors is number of bit errors which can be corrected in format information and version information
+const maxBCHErrors = 3
+
+// decodedSymbol is the result of decoding a symbol
+type decodedSymbol struct {
+	version  int
+	ecl      ErrorCorrectionLevel
+	mask     uint8
+	segments []Segment
+	content  string
+}
+
+// DecodeMatrix decodes QR code from modules, and returns the content
+// modules[y][x] is true if the module is dark, and light modules around the symbol are ignored as quiet zone
+// format information, version information and codewords are corrected if they have errors within capacity
+// Micro QR code and rMQR are not supported
+func DecodeMatrix(modules [][]bool) (string, error) {
+	d, err := decodeMatrix(modules)
+	if err != nil {
+		return "", err
+	}
+	return d.content, nil
+}
+
+// Decode finds QR code in img and returns the content
+// img is binarized by local thresholds, and modules are sampled by perspective transform from finder patterns and alignment pattern,
+// so that photos of printed symbols can be decoded
+func Decode(img image.Image) (string, error) {
+	lum, width, height := luminances(img)
+	m := binarize(lum, width, height)
+
+	triples := finderTriples(m.findFinderPatterns())
+	if len(triples) == 0 {
+		return "", fmt.Errorf("QR code is not found")
+	}
+
+	var err error
+	for _, t := range triples {
+		var s decodedSymbol
+		s, _, err = m.decodeTriple(t)
+		if err == nil {
+			return s.content, nil
+		}
+	}
+	return "", fmt.Errorf("QR code cannot be decoded: %w", err)
+}
+
+// Result is a QR code found in an image
+type Result struct {
+	Content string
+	// Corners are corners of the symbol without quiet zone in order of top left, top right, bottom right and bottom left
+	Corners [4]image.Point
+	Version int
+	ECL     ErrorCorrectionLevel
+}
+
+// DecodeAll finds all QR codes in img and returns them
+// candidate symbols are made from triples of finder patterns, and triples using finder patterns of decoded symbols are skipped
+func DecodeAll(img image.Image) ([]Result, error) {
+	lum, width, height := luminances(img)
+	m := binarize(lum, width, height)
+
+	triples := finderTriples(m.findFinderPatterns())
+	if len(triples) == 0 {
+		return nil, fmt.Errorf("QR code is not found")
+	}
+
+	var results []Result
+	var decoded [][4]point
+	err := fmt.Errorf("QR code is not found")
+	for _, t := range triples {
+		if inDecodedSymbol(decoded, t) {
+			continue
+		}
+
+		s, corners, e := m.decodeTriple(t)
+		if e != nil {
+			err = e
+			continue
+		}
+		decoded = append(decoded, corners)
+
+		r := Result{Content: s.content, Version: s.version, ECL: s.ecl}
+		for i, c := range corners {
+			r.Corners[i] = image.Pt(int(math.Round(c.x)), int(math.Round(c.y)))
+		}
+		results = append(results, r)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("QR code cannot be decoded: %w", err)
+	}
+	return results, nil
+}
+
+// inDecodedSymbol returns true if any finder pattern of t is in decoded symbols
+func inDecodedSymbol(decoded [][4]point, t finderTriple) bool {
+	for _, corners := range decoded {
+		for _, p := range []patternCandidate{t.topLeft, t.topRight, t.bottomLeft} {
+			if contains(corners, p.point) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DecodeReader decodes PNG, JPEG or GIF image from r, and returns the content of QR code in it
+func DecodeReader(r io.Reader) (string, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return "", err
+	}
+	return Decode(img)
+}
+
+func decodeMatrix(modules [][]bool) (decodedSymbol, error) {
+	grid, err := trimQuietZone(modules)
+	if err != nil {
+		return decodedSymbol{}, err
+	}
+	return decodeGrid(grid)
+}
+
+// decodeGrid decodes modules of a symbol without quiet zone
+func decodeGrid(grid [][]bool) (decodedSymbol, error) {
+	size := len(grid)
+	if len(grid[0]) != size || size < symbolSize(minVersion) || size > symbolSize(maxVersion) || (size-symbolSize(minVersion))%4 != 0 {
+		return decodedSymbol{}, fmt.Errorf("symbol size %dx%d is not a size of QR code", len(grid[0]), size)
+	}
+	version := (size-symbolSize(minVersion))/4 + minVersion
+
+	ecl, mask, err := readFormatInfo(grid)
+	if err != nil {
+		return decodedSymbol{}, err
+	}
+
+	if version >= minVersionInfoVersion {
+		v, err := readVersionInfo(grid)
+		if err != nil {
+			return decodedSymbol{}, err
+		}
+		if v != version {
+			return decodedSymbol{}, fmt.Errorf("version information shows version %d, but symbol size is version %d", v, version)
+		}
+	}
+
+	codewords := readCodeWords(grid, version, ecl, mask)
+	data, err := correctCodeWords(codewords, ecBlocks[ecl][version-1])
+	if err != nil {
+		return decodedSymbol{}, err
+	}
+
+	segments, err := parseSegments(data, version)
+	if err != nil {
+		return decodedSymbol{}, err
+	}
+	content, err := segmentsContent(segments)
+	if err != nil {
+		return decodedSymbol{}, err
+	}
+
+	return decodedSymbol{
+		version:  version,
+		ecl:      ecl,
+		mask:     mask,
+		segments: segments,
+		content:  content,
+	}, nil
+}
+
+// trimQuietZone returns modules in the smallest rectangle which has all dark modules
+// corners of the symbol are dark because they are parts of finder patterns
+func trimQuietZone(modules [][]bool) ([][]bool, error) {
+	top, bottom, left, right := -1, -1, -1, -1
+	for y, row := range modules {
+		if len(row) != len(modules[0]) {
+			return nil, fmt.Errorf("row %d has %d modules, but row 0 has %d modules", y, len(row), len(modules[0]))
+		}
+		for x, v := range row {
+			if !v {
+				continue
+			}
+			if top < 0 {
+				top = y
+			}
+			bottom = y
+			if left < 0 || x < left {
+				left = x
+			}
+			if x > right {
+				right = x
+			}
+		}
+	}
+	if top < 0 {
+		return nil, fmt.Errorf("modules have no dark module")
+	}
+
+	grid := make([][]bool, bottom-top+1)
+	for i := range grid {
+		grid[i] = modules[top+i][left : right+1]
+	}
+	return grid, nil
+}
+
+// readFormatInfo returns error correction level and mask pattern from format information
+// both copies of format information are compared with all masked BCH codes, and the nearest one is used
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.9.1 and Annex C
+func readFormatInfo(grid [][]bool) (ErrorCorrectionLevel, uint8, error) {
+	size := len(grid)
+
+	// format information is placed in the same positions as addVerticalFormatInfo and addHorizontalFormatInfo
+	var vertical, horizontal uint16
+	for i := 0; i < formatInfoLength; i++ {
+		var vy int
+		switch {
+		case i <= 5:
+			vy = i
+		case i <= 7:
+			vy = i + 1
+		default:
+			vy = size - finderPatternSize - 8 + i
+		}
+
+		var hx int
+		switch {
+		case i <= 7:
+			hx = size - i - 1
+		case i == 8:
+			hx = finderPatternSize
+		default:
+			hx = 14 - i
+		}
+
+		if grid[vy][finderPatternSize+1] {
+			vertical |= 1 << i
+		}
+		if grid[finderPatternSize+1][hx] {
+			horizontal |= 1 << i
+		}
+	}
+
+	best, distance := -1, maxBCHErrors+1
+	for i, fi := range maskedBitSequence {
+		for _, read := range []uint16{vertical, horizontal} {
+			if d := bits.OnesCount16(fi ^ read); d < distance {
+				best, distance = i, d
+			}
+		}
+	}
+	if best < 0 {
+		return 0, 0, fmt.Errorf("format information cannot be corrected")
+	}
+	return ErrorCorrectionLevel(best >> 3), uint8(best & 0b111), nil
+}
+
+// readVersionInfo returns version from version information
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.10 and Annex D
+func readVersionInfo(grid [][]bool) (int, error) {
+	size := len(grid)
+
+	// version information is placed in the same positions as addVersionInfo
+	var bottomLeft, topRight uint32
+	for i := 0; i < versionInfoLength; i++ {
+		if grid[size-finderPatternSize-4+i%3][i/3] {
+			bottomLeft |= 1 << i
+		}
+		if grid[i/3][size-finderPatternSize-4+i%3] {
+			topRight |= 1 << i
+		}
+	}
+
+	best, distance := -1, maxBCHErrors+1
+	for i, vi := range versionBitSequence {
+		for _, read := range []uint32{bottomLeft, topRight} {
+			if d := bits.OnesCount32(vi ^ read); d < distance {
+				best, distance = i, d
+			}
+		}
+	}
+	if best < 0 {
+		return 0, fmt.Errorf("version information cannot be corrected")
+	}
+	return best + minVersionInfoVersion, nil
+}
+
+// readCodeWords reads codewords from data modules and releases mask
+// remainder bits are not read
+func readCodeWords(grid [][]bool, version int, ecl ErrorCorrectionLevel, mask uint8) []byte {
+	// function patterns of the symbol are needed to skip them in the same way as addData
+	template := newQRCode(version, ecl, mask, bitset.NewBitSet(0))
+
+	total := totalCodeWords(version, ecl)
+	bs := bitset.NewBitSet(total * 8)
+	template.forEachDataModule(total*8, func(i, x, y int) {
+		bs.SetBool(grid[y][x] != calculateMask(x, y, mask))
+	})
+
+	codewords := make([]byte, total)
+	for i := range codewords {
+		codewords[i] = bs.ByteAt(i)
+	}
+	return codewords
+}
+
+// deinterleaveBlocks splits codewords into error correction blocks in reverse of interleaveBlocks
+func deinterleaveBlocks(groups []ecBlock, codewords []byte) []codeBlock {
+	var blocks []codeBlock
+	for _, g := range groups {
+		for i := 0; i < g.count; i++ {
+			blocks = append(blocks, codeBlock{
+				data: make([]byte, 0, g.dataCodeWords),
+				ecc:  make([]byte, 0, g.totalCodeWords-g.dataCodeWords),
+			})
+		}
+	}
+
+	pos := 0
+	for {
+		added := false
+		for i := range blocks {
+			if len(blocks[i].data) < cap(blocks[i].data) {
+				blocks[i].data = append(blocks[i].data, codewords[pos])
+				pos++
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	for {
+		added := false
+		for i := range blocks {
+			if len(blocks[i].ecc) < cap(blocks[i].ecc) {
+				blocks[i].ecc = append(blocks[i].ecc, codewords[pos])
+				pos++
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return blocks
+}
+
+// correctCodeWords corrects errors of each block and returns data codewords
+func correctCodeWords(codewords []byte, groups []ecBlock) (*bitset.BitSet, error) {
+	blocks := deinterleaveBlocks(groups, codewords)
+
+	var data []byte
+	for i, b := range blocks {
+		corrected, _, err := reedsolomon.Decode(append(b.data, b.ecc...), len(b.ecc))
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		data = append(data, corrected...)
+	}
+
+	bs := bitset.NewBitSet(len(data) * 8)
+	bs.SetBytes(data)
+	return bs, nil
+}
+
+// bitReader reads bits from the head of bitset
+type bitReader struct {
+	bs  *bitset.BitSet
+	pos int
+}
+
+// remaining returns number of bits which are not read
+func (r *bitReader) remaining() int {
+	return r.bs.Length() - r.pos
+}
+
+// readInt reads length bits as unsigned integer
+func (r *bitReader) readInt(length int) (int, error) {
+	if length > r.remaining() {
+		return 0, fmt.Errorf("%d bits are needed, but only %d bits remain", length, r.remaining())
+	}
+	v := 0
+	for i := 0; i < length; i++ {
+		v <<= 1
+		if r.bs.GetValue(r.pos) {
+			v |= 1
+		}
+		r.pos++
+	}
+	return v, nil
+}
+
+// parseSegments parses data bits into segments until terminator
+// Data of segments has the same form as segments which are encoded
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.4
+func parseSegments(data *bitset.BitSet, version int) ([]Segment, error) {
+	r := &bitReader{bs: data}
+
+	var segments []Segment
+	// terminator can be shortened or omitted at the end of data
+	for r.remaining() >= modeCharCount {
+		m, _ := r.readInt(modeCharCount)
+		if m == 0 {
+			break
+		}
+		mode := ModeIndicator(m)
+
+		s, err := parseSegment(r, mode, version)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d (%s): %w", len(segments), mode, err)
+		}
+		segments = append(segments, s)
+	}
+	return segments, nil
+}
+
+func parseSegment(r *bitReader, mode ModeIndicator, version int) (Segment, error) {
+	switch mode {
+	case ECI:
+		assignment, err := readECIDesignator(r)
+		if err != nil {
+			return Segment{}, err
+		}
+		return ECISegment(assignment), nil
+	case StructuredAppend:
+		header := make([]byte, structuredAppendHeaderBits/8)
+		for i := range header {
+			v, err := r.readInt(8)
+			if err != nil {
+				return Segment{}, err
+			}
+			header[i] = byte(v)
+		}
+		return Segment{Mode: mode, Data: string(header)}, nil
+	case FNC1First:
+		return FNC1FirstSegment(), nil
+	case FNC1Second:
+		v, err := r.readInt(applicationIndicatorBits)
+		if err != nil {
+			return Segment{}, err
+		}
+		return Segment{Mode: mode, Data: applicationIndicatorString(v)}, nil
+	case Hanzi:
+		subset, err := r.readInt(hanziSubsetBits)
+		if err != nil {
+			return Segment{}, err
+		}
+		if subset != hanziSubsetGB2312 {
+			return Segment{}, fmt.Errorf("hanzi subset %04b is not supported", subset)
+		}
+	case Numeric, AlphaNumeric, EightBits, Kanji:
+	default:
+		return Segment{}, fmt.Errorf("unknown mode indicator")
+	}
+
+	count, err := r.readInt(characterCountIndicatorBits(version, mode))
+	if err != nil {
+		return Segment{}, err
+	}
+
+	var data string
+	switch mode {
+	case Numeric:
+		data, err = readNumericData(r, count)
+	case AlphaNumeric:
+		data, err = readAlphaNumericData(r, count)
+	case EightBits:
+		data, err = readEightBitsData(r, count)
+	case Kanji:
+		data, err = readKanjiData(r, count)
+	case Hanzi:
+		data, err = readHanziData(r, count)
+	}
+	if err != nil {
+		return Segment{}, err
+	}
+	return Segment{Mode: mode, Data: data}, nil
+}
+
+// readECIDesignator reads ECI designator in reverse of addECIDesignator
+func readECIDesignator(r *bitReader) (int, error) {
+	// number of leading 1 bits shows length of designator
+	prefix := 0
+	for prefix < 3 {
+		v, err := r.readInt(1)
+		if err != nil {
+			return 0, err
+		}
+		if v == 0 {
+			break
+		}
+		prefix++
+	}
+
+	switch prefix {
+	case 0:
+		return r.readInt(7)
+	case 1:
+		return r.readInt(14)
+	case 2:
+		return r.readInt(21)
+	default:
+		return 0, fmt.Errorf("invalid ECI designator")
+	}
+}
+
+// applicationIndicatorString returns application indicator of the value in reverse of applicationIndicator
+func applicationIndicatorString(v int) string {
+	if v >= 100 {
+		return string(rune(v - 100))
+	}
+	return fmt.Sprintf("%02d", v)
+}
+
+// readNumericData reads count digits in reverse of addNumericData
+func readNumericData(r *bitReader, count int) (string, error) {
+	// bit length for each number of digits
+	bitLengths := []int{0, 4, 7, 10}
+
+	var sb strings.Builder
+	for count > 0 {
+		digits := 3
+		if count < 3 {
+			digits = count
+		}
+		v, err := r.readInt(bitLengths[digits])
+		if err != nil {
+			return "", err
+		}
+		s := fmt.Sprintf("%0*d", digits, v)
+		if len(s) != digits {
+			return "", fmt.Errorf("%d is invalid for %d digits", v, digits)
+		}
+		sb.WriteString(s)
+		count -= digits
+	}
+	return sb.String(), nil
+}
+
+// readAlphaNumericData reads count characters in reverse of addAlphaNumericData
+func readAlphaNumericData(r *bitReader, count int) (string, error) {
+	var sb strings.Builder
+	for count > 0 {
+		if count == 1 {
+			v, err := r.readInt(6)
+			if err != nil {
+				return "", err
+			}
+			if v >= len(alphaNumericTable) {
+				return "", fmt.Errorf("%d is not a value of alpha numeric character", v)
+			}
+			sb.WriteByte(alphaNumericTable[v])
+			break
+		}
+
+		v, err := r.readInt(11)
+		if err != nil {
+			return "", err
+		}
+		if v >= len(alphaNumericTable)*len(alphaNumericTable) {
+			return "", fmt.Errorf("%d is not a value of alpha numeric characters", v)
+		}
+		sb.WriteByte(alphaNumericTable[v/45])
+		sb.WriteByte(alphaNumericTable[v%45])
+		count -= 2
+	}
+	return sb.String(), nil
+}
+
+// readEightBitsData reads count bytes as they are
+// the bytes are interpreted by ECI in segmentsContent
+func readEightBitsData(r *bitReader, count int) (string, error) {
+	data := make([]byte, count)
+	for i := range data {
+		v, err := r.readInt(8)
+		if err != nil {
+			return "", err
+		}
+		data[i] = byte(v)
+	}
+	return string(data), nil
+}
+
+// readKanjiData reads count characters in reverse of addKanjiData
+func readKanjiData(r *bitReader, count int) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < count; i++ {
+		v, err := r.readInt(13)
+		if err != nil {
+			return "", err
+		}
+
+		// 0x0000-0x1EBC => 0x8140-0x9FFC, 0x1F00-0x2A7F => 0xE040-0xEBBF
+		code := (v/0xC0)<<8 | v%0xC0
+		if code <= 0x9FFC-0x8140 {
+			code += 0x8140
+		} else {
+			code += 0xC140
+		}
+
+		c, ok := charset.ShiftJISRune(uint16(code))
+		if !ok {
+			return "", fmt.Errorf("%#x is not a kanji character", code)
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String(), nil
+}
+
+// readHanziData reads count characters in reverse of addHanziData
+func readHanziData(r *bitReader, count int) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < count; i++ {
+		v, err := r.readInt(13)
+		if err != nil {
+			return "", err
+		}
+
+		// 0x0000-0x09FE => 0xA1A1-0xAAFE, 0x0A00-0x545D => 0xB0A1-0xFAFE
+		code := (v/0x60)<<8 | v%0x60
+		if code <= 0xAAFE-0xA1A1 {
+			code += 0xA1A1
+		} else {
+			code += 0xA6A1
+		}
+
+		c, ok := charset.GB2312Rune(uint16(code))
+		if !ok {
+			return "", fmt.Errorf("%#x is not a hanzi character", code)
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String(), nil
+}
+
+// segmentsContent returns content of segments
+// 8 bits byte data is converted to UTF-8 by the character set of ECI,
+// and "%" of alpha numeric data is converted to GS in FNC1 mode
+func segmentsContent(segments []Segment) (string, error) {
+	var sb strings.Builder
+
+	// assignment is -1 until ECI is declared
+	assignment := -1
+	fnc1 := false
+	for _, s := range segments {
+		switch s.Mode {
+		case ECI:
+			assignment, _ = eciAssignment(s.Data)
+		case FNC1First, FNC1Second:
+			fnc1 = true
+		case AlphaNumeric:
+			if fnc1 {
+				sb.WriteString(unescapeFNC1(s.Data))
+			} else {
+				sb.WriteString(s.Data)
+			}
+		case EightBits:
+			decoded, err := decodeBytes(s.Data, assignment)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(decoded)
+		case Numeric, Kanji, Hanzi:
+			sb.WriteString(s.Data)
+		}
+	}
+	return sb.String(), nil
+}
+
+// unescapeFNC1 converts "%%" to "%" and "%" to GS in reverse of alphaNumericChars
+func unescapeFNC1(data string) string {
+	var sb strings.Builder
+	for i := 0; i < len(data); i++ {
+		if data[i] != '%' {
+			sb.WriteByte(data[i])
+			continue
+		}
+		if i+1 < len(data) && data[i+1] == '%' {
+			sb.WriteByte('%')
+			i++
+			continue
+		}
+		sb.WriteRune(groupSeparator)
+	}
+	return sb.String()
+}
+
+// decodeBytes converts data of 8 bits byte mode to UTF-8 by the character set of ECI assignment
+// data is regarded as UTF-8 without ECI because this package encodes content in UTF-8,
+// and it is regarded as ISO/IEC 8859-1 of the default interpretation if it is not valid UTF-8
+func decodeBytes(data string, assignment int) (string, error) {
+	switch {
+	case assignment < 0:
+		if utf8.ValidString(data) {
+			return data, nil
+		}
+		return decodeISO8859(data, 1)
+	case assignment == ECI_UTF8:
+		return data, nil
+	case assignment == ECI_ShiftJIS:
+		return decodeShiftJIS(data)
+	case assignment == 1 || assignment == ECI_ISO8859_1:
+		return decodeISO8859(data, 1)
+	case 4 <= assignment && assignment <= 18 && assignment != 14:
+		// assignment 4-13 and 15-18 are ISO/IEC 8859-2 to 8859-11 and 8859-13 to 8859-16
+		return decodeISO8859(data, assignment-2)
+	default:
+		return "", fmt.Errorf("ECI assignment number %d is not supported", assignment)
+	}
+}
+
+func decodeISO8859(data string, part int) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(data); i++ {
+		c, ok := charset.ISO8859Rune(part, data[i])
+		if !ok {
+			return "", fmt.Errorf("%#x is not a character of ISO/IEC 8859-%d", data[i], part)
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String(), nil
+}
+
+// decodeShiftJIS converts Shift_JIS byte sequence in reverse of charset.ShiftJISBytes
+func decodeShiftJIS(data string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case b < 0x80:
+			sb.WriteByte(b)
+		case 0xA1 <= b && b <= 0xDF:
+			// half-width katakana: 0xA1-0xDF => U+FF61-U+FF9F
+			sb.WriteRune(rune(b) - 0xA1 + 0xFF61)
+		default:
+			if i+1 >= len(data) {
+				return "", fmt.Errorf("Shift_JIS double byte code is not terminated")
+			}
+			code := uint16(b)<<8 | uint16(data[i+1])
+			c, ok := charset.ShiftJISRune(code)
+			if !ok {
+				return "", fmt.Errorf("%#x is not a character of Shift_JIS", code)
+			}
+			sb.WriteRune(c)
+			i++
+		}
+	}
+	return sb.String(), nil
+}
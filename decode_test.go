@@ -0,0 +1,249 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		opts    Options
+	}{
+		{
+			name:    "numeric",
+			content: "01234567890123",
+			opts:    Options{ECL: ECL_Medium},
+		},
+		{
+			name:    "alpha numeric",
+			content: "HELLO WORLD $%*+-./:",
+			opts:    Options{ECL: ECL_Low},
+		},
+		{
+			name:    "mixed segments",
+			content: "Hello, World 0123456789 点茗",
+			opts:    Options{ECL: ECL_High},
+		},
+		{
+			name:    "version information",
+			content: strings.Repeat("QR CODE 2018 ", 20),
+			opts:    Options{ECL: ECL_Highest},
+		},
+		{
+			name:    "version 40",
+			content: strings.Repeat("a", 1200),
+			opts:    Options{ECL: ECL_Highest, MinVersion: 40},
+		},
+		{
+			name:    "UTF-8 ECI",
+			content: "Grüße",
+			opts:    Options{ECL: ECL_Medium, UTF8ECI: true},
+		},
+		{
+			name:    "transcoded to ISO/IEC 8859-7",
+			content: "Καλημέρα",
+			opts:    Options{ECL: ECL_Medium, Transcode: true},
+		},
+		{
+			name:    "transcoded to Shift_JIS",
+			content: "ｱｲｳ「こんにちは」",
+			opts:    Options{ECL: ECL_Medium, Transcode: true},
+		},
+		{
+			name:    "GS1",
+			content: "0109506000134352" + "10ABC%123\x1d" + "17201225",
+			opts:    Options{ECL: ECL_Medium, GS1: true},
+		},
+		{
+			name:    "application indicator",
+			content: "AB-C10%",
+			opts:    Options{ECL: ECL_Medium, ApplicationIndicator: "37"},
+		},
+		{
+			name:    "hanzi",
+			content: "汉字编码",
+			opts:    Options{ECL: ECL_Medium, Hanzi: true},
+		},
+		{
+			name:    "empty",
+			content: "",
+			opts:    Options{ECL: ECL_Medium},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := NewWithOptions(test.content, test.opts)
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+
+			got, err := DecodeMatrix(q.modules)
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if got != test.content {
+				t.Errorf("expected %q, got %q\n", test.content, got)
+			}
+		})
+	}
+}
+
+func TestDecodeMatrix_AllVersions(t *testing.T) {
+	for version := minVersion; version <= maxVersion; version++ {
+		// error correction levels and mask patterns are changed in turn
+		ecl := eclOrder[version%len(eclOrder)]
+		mask := uint8(version % 8)
+		content := strings.Repeat("7", characterCapacity(version, ecl, Numeric))
+
+		q, err := NewWithOptions(content, Options{ECL: ecl, MinVersion: version, MaxVersion: version, Mask: &mask})
+		if err != nil {
+			t.Fatalf("version %d: %v\n", version, err)
+		}
+
+		d, err := decodeMatrix(q.modules)
+		if err != nil {
+			t.Fatalf("version %d: %v\n", version, err)
+		}
+		if d.version != version || d.ecl != ecl || d.mask != mask {
+			t.Errorf("version %d, ecl %d, mask %d is expected, got version %d, ecl %d, mask %d\n", version, ecl, mask, d.version, d.ecl, d.mask)
+		}
+		if d.content != content {
+			t.Errorf("version %d: expected %q, got %q\n", version, content, d.content)
+		}
+	}
+}
+
+func TestDecodeMatrix_StructuredAppend(t *testing.T) {
+	content := strings.Repeat("structured append ", 10)
+	codes, err := NewStructuredAppend(ECL_Medium, content, 2)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	var sb strings.Builder
+	for i, q := range codes {
+		d, err := decodeMatrix(q.modules)
+		if err != nil {
+			t.Fatalf("error: %v\n", err)
+		}
+		if d.segments[0].Mode != StructuredAppend {
+			t.Errorf("the first segment is expected structured append, got %s\n", d.segments[0].Mode)
+		}
+		if got := int(d.segments[0].Data[0] >> 4); got != i {
+			t.Errorf("expected index %d, got %d\n", i, got)
+		}
+		sb.WriteString(d.content)
+	}
+	if sb.String() != content {
+		t.Errorf("expected %q, got %q\n", content, sb.String())
+	}
+}
+
+func TestDecodeMatrix_Errors(t *testing.T) {
+	content := "https://example.com/qrcode"
+
+	tests := []struct {
+		name    string
+		damage  func(modules [][]bool, size int)
+		wantErr bool
+	}{
+		{
+			name: "data modules are damaged",
+			damage: func(modules [][]bool, size int) {
+				// bottom right 3 x 3 modules are in the first codewords
+				for y := size - 3; y < size; y++ {
+					for x := size - 3; x < size; x++ {
+						modules[quietZoneSize+y][quietZoneSize+x] = !modules[quietZoneSize+y][quietZoneSize+x]
+					}
+				}
+			},
+		},
+		{
+			name: "format information is damaged",
+			damage: func(modules [][]bool, size int) {
+				for x := 0; x < 3; x++ {
+					modules[quietZoneSize+8][quietZoneSize+x] = !modules[quietZoneSize+8][quietZoneSize+x]
+				}
+			},
+		},
+		{
+			name: "dirt in quiet zone",
+			damage: func(modules [][]bool, size int) {
+				modules[0][0] = true
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many errors",
+			damage: func(modules [][]bool, size int) {
+				for y := 10; y < size; y++ {
+					for x := 10; x < size; x++ {
+						modules[quietZoneSize+y][quietZoneSize+x] = !modules[quietZoneSize+y][quietZoneSize+x]
+					}
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := New(ECL_High, content)
+			if err != nil {
+				t.Fatalf("error: %v\n", err)
+			}
+			test.damage(q.modules, q.width)
+
+			got, err := DecodeMatrix(q.modules)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("error is expected, but got nil\n")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if got != content {
+				t.Errorf("expected %q, got %q\n", content, got)
+			}
+		})
+	}
+}
+
+func TestDecodeMatrix_InvalidModules(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules [][]bool
+	}{
+		{
+			name: "no module",
+		},
+		{
+			name:    "no dark module",
+			modules: [][]bool{{false, false}, {false, false}},
+		},
+		{
+			name:    "not square",
+			modules: [][]bool{{true, true, true}, {true, true, true}},
+		},
+		{
+			name:    "rows have different lengths",
+			modules: [][]bool{{true, true}, {true}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := DecodeMatrix(test.modules); err == nil {
+				t.Errorf("error is expected, but got nil\n")
+			}
+		})
+	}
+}
@@ -0,0 +1,882 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+const (
+	// binarizeWindowBlocks is width of window in blocks to calculate local threshold
+	binarizeWindowBlocks = 5
+
+	// minBinarizeContrast is minimum difference of luminance in window to use local threshold
+	minBinarizeContrast = 24
+)
+
+// finderRatios shows widths of dark, light, dark, light and dark modules across the center of finder pattern
+var finderRatios = [5]float64{1, 1, 3, 1, 1}
+
+// bitMatrix is binarized image, and true means dark pixel
+type bitMatrix struct {
+	width  int
+	height int
+	bits   []bool
+}
+
+// get returns false for positions out of the image, because they are regarded as quiet zone
+func (m *bitMatrix) get(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.width || y >= m.height {
+		return false
+	}
+	return m.bits[y*m.width+x]
+}
+
+// luminances converts img to grayscale
+// transparent pixels are composed over white background
+func luminances(img image.Image) ([]uint8, int, int) {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	lum := make([]uint8, width*height)
+
+	switch src := img.(type) {
+	case *image.YCbCr:
+		// Y of JPEG image is luminance
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				lum[y*width+x] = src.Y[src.YOffset(b.Min.X+x, b.Min.Y+y)]
+			}
+		}
+	case *image.Gray:
+		for y := 0; y < height; y++ {
+			copy(lum[y*width:(y+1)*width], src.Pix[src.PixOffset(b.Min.X, b.Min.Y+y):])
+		}
+	default:
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+				// colors are alpha-premultiplied, so white background is added as 0xFFFF - a
+				white := 0xFFFF - a
+				v := (299*(r+white) + 587*(g+white) + 114*(bl+white)) / 1000
+				lum[y*width+x] = uint8(v >> 8)
+			}
+		}
+	}
+	return lum, width, height
+}
+
+// binarize converts luminances to bitMatrix by local thresholds
+// threshold of each block is the middle of the darkest and the lightest pixel in the window around the block,
+// and global threshold by Otsu's method is used if the window has low contrast, such as inside of a large module
+func binarize(lum []uint8, width, height int) *bitMatrix {
+	m := &bitMatrix{width: width, height: height, bits: make([]bool, width*height)}
+	if width == 0 || height == 0 {
+		return m
+	}
+
+	blockSize := width
+	if height < blockSize {
+		blockSize = height
+	}
+	blockSize /= 40
+	if blockSize < 8 {
+		blockSize = 8
+	}
+
+	blocksX := (width + blockSize - 1) / blockSize
+	blocksY := (height + blockSize - 1) / blockSize
+	blockMin := make([]uint8, blocksX*blocksY)
+	blockMax := make([]uint8, blocksX*blocksY)
+	for by := 0; by < blocksY; by++ {
+		for bx := 0; bx < blocksX; bx++ {
+			lo, hi := uint8(255), uint8(0)
+			for y := by * blockSize; y < (by+1)*blockSize && y < height; y++ {
+				for x := bx * blockSize; x < (bx+1)*blockSize && x < width; x++ {
+					v := lum[y*width+x]
+					if v < lo {
+						lo = v
+					}
+					if v > hi {
+						hi = v
+					}
+				}
+			}
+			blockMin[by*blocksX+bx] = lo
+			blockMax[by*blocksX+bx] = hi
+		}
+	}
+
+	global := otsuThreshold(lum)
+	half := binarizeWindowBlocks / 2
+	for by := 0; by < blocksY; by++ {
+		for bx := 0; bx < blocksX; bx++ {
+			lo, hi := uint8(255), uint8(0)
+			for wy := by - half; wy <= by+half; wy++ {
+				for wx := bx - half; wx <= bx+half; wx++ {
+					if wx < 0 || wy < 0 || wx >= blocksX || wy >= blocksY {
+						continue
+					}
+					if blockMin[wy*blocksX+wx] < lo {
+						lo = blockMin[wy*blocksX+wx]
+					}
+					if blockMax[wy*blocksX+wx] > hi {
+						hi = blockMax[wy*blocksX+wx]
+					}
+				}
+			}
+
+			threshold := global
+			if int(hi)-int(lo) >= minBinarizeContrast {
+				threshold = (int(lo) + int(hi) + 1) / 2
+			}
+			for y := by * blockSize; y < (by+1)*blockSize && y < height; y++ {
+				for x := bx * blockSize; x < (bx+1)*blockSize && x < width; x++ {
+					m.bits[y*width+x] = int(lum[y*width+x]) < threshold
+				}
+			}
+		}
+	}
+	return m
+}
+
+// otsuThreshold returns threshold which maximizes variance between dark and light pixels
+func otsuThreshold(lum []uint8) int {
+	var histogram [256]int
+	for _, v := range lum {
+		histogram[v]++
+	}
+
+	total := len(lum)
+	sum := 0
+	for v, n := range histogram {
+		sum += v * n
+	}
+
+	best, bestVariance := 128, -1.0
+	darkCount, darkSum := 0, 0
+	for t := 1; t < 256; t++ {
+		// pixels whose luminance is less than t are dark
+		darkCount += histogram[t-1]
+		darkSum += (t - 1) * histogram[t-1]
+		lightCount := total - darkCount
+		if darkCount == 0 || lightCount == 0 {
+			continue
+		}
+
+		darkMean := float64(darkSum) / float64(darkCount)
+		lightMean := float64(sum-darkSum) / float64(lightCount)
+		variance := float64(darkCount) * float64(lightCount) * (darkMean - lightMean) * (darkMean - lightMean)
+		if variance > bestVariance {
+			best, bestVariance = t, variance
+		}
+	}
+	return best
+}
+
+// point is a position in image
+type point struct {
+	x float64
+	y float64
+}
+
+func distance(a, b point) float64 {
+	return math.Hypot(a.x-b.x, a.y-b.y)
+}
+
+// patternCandidate is a center of finder pattern or alignment pattern found in image
+type patternCandidate struct {
+	point
+	moduleSize float64
+
+	// count is number of rows where the pattern is found
+	count int
+}
+
+// matchesFinderRatios returns module size if widths of runs are in proportion to finderRatios
+// each width may differ by half of the expected width
+func matchesFinderRatios(counts [5]int) (float64, bool) {
+	total, units := 0, 0.0
+	for i, c := range counts {
+		if c == 0 {
+			return 0, false
+		}
+		total += c
+		units += finderRatios[i]
+	}
+	if float64(total) < units {
+		return 0, false
+	}
+
+	moduleSize := float64(total) / units
+	for i, c := range counts {
+		expected := finderRatios[i] * moduleSize
+		if math.Abs(float64(c)-expected) >= expected/2 {
+			return 0, false
+		}
+	}
+	return moduleSize, true
+}
+
+// scanFinderRuns finds runs which match finderRatios in the row y, and calls found with the runs and the end of runs
+func (m *bitMatrix) scanFinderRuns(y int, found func(counts [5]int, end int)) {
+	var counts [5]int
+	state := 0
+	for x := 0; x < m.width; x++ {
+		if m.get(x, y) {
+			// light to dark
+			if state == 1 || state == 3 {
+				state++
+			}
+			counts[state]++
+			continue
+		}
+
+		switch state {
+		case 0:
+			// leading light pixels are skipped
+			if counts[0] > 0 {
+				state++
+				counts[state]++
+			}
+		case 2:
+			state++
+			counts[state]++
+		case 4:
+			// dark to light at the end of runs
+			if _, ok := matchesFinderRatios(counts); ok {
+				found(counts, x)
+			}
+			counts = [5]int{counts[2], counts[3], counts[4], 1, 0}
+			state = 3
+		default:
+			counts[state]++
+		}
+	}
+	if state == 4 {
+		if _, ok := matchesFinderRatios(counts); ok {
+			found(counts, m.width)
+		}
+	}
+}
+
+// crossCheck counts runs which match finderRatios through (x, y) in the direction (dx, dy)
+// it returns offset of the center from (x, y) in steps and total length of runs
+// the center run and the outer runs must not be longer than maxCount
+func (m *bitMatrix) crossCheck(x, y, dx, dy int, maxCount int) (float64, int, bool) {
+	if !m.get(x, y) {
+		return 0, 0, false
+	}
+
+	var counts [5]int
+	// backward from the center
+	i := 0
+	for m.get(x-i*dx, y-i*dy) && counts[2] <= maxCount {
+		counts[2]++
+		i++
+	}
+	for state := 1; state >= 0; state-- {
+		dark := state == 0
+		for m.inside(x-i*dx, y-i*dy) && m.get(x-i*dx, y-i*dy) == dark && counts[state] <= maxCount {
+			counts[state]++
+			i++
+		}
+		if counts[state] == 0 || counts[state] > maxCount {
+			return 0, 0, false
+		}
+	}
+	begin := i
+
+	// forward from the center
+	i = 1
+	for m.get(x+i*dx, y+i*dy) && counts[2] <= maxCount {
+		counts[2]++
+		i++
+	}
+	for state := 3; state <= 4; state++ {
+		dark := state == 4
+		for m.inside(x+i*dx, y+i*dy) && m.get(x+i*dx, y+i*dy) == dark && counts[state] <= maxCount {
+			counts[state]++
+			i++
+		}
+		if counts[state] == 0 || counts[state] > maxCount {
+			return 0, 0, false
+		}
+	}
+	end := i
+
+	if _, ok := matchesFinderRatios(counts); !ok {
+		return 0, 0, false
+	}
+
+	// center of the center run
+	center := float64(-begin+counts[0]+counts[1]) + float64(counts[2])/2 + 0.5
+	return center, end + begin - 1, true
+}
+
+func (m *bitMatrix) inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < m.width && y < m.height
+}
+
+// findFinderPatterns returns centers of finder patterns in the image
+// each row is scanned for runs of 1:1:3:1:1, and they are checked in vertical, horizontal and diagonal directions
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 12
+func (m *bitMatrix) findFinderPatterns() []patternCandidate {
+	var candidates []patternCandidate
+	for y := 0; y < m.height; y++ {
+		m.scanFinderRuns(y, func(counts [5]int, end int) {
+			total := counts[0] + counts[1] + counts[2] + counts[3] + counts[4]
+			x := end - counts[4] - counts[3] - counts[2]/2 - 1
+
+			c, ok := m.checkFinder(x, y, total, counts[2]*2)
+			if !ok {
+				return
+			}
+			candidates = mergeCandidate(candidates, c)
+		})
+	}
+
+	// true finder patterns are found in several rows
+	var result []patternCandidate
+	for _, c := range candidates {
+		if c.count >= 2 {
+			result = append(result, c)
+		}
+	}
+	if len(result) < 3 {
+		result = candidates
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].count > result[j].count
+	})
+	return result
+}
+
+// checkFinder checks runs through (x, y) vertically, horizontally and diagonally
+// total is length of runs in the row, and it returns the refined center
+func (m *bitMatrix) checkFinder(x, y int, total int, maxCount int) (patternCandidate, bool) {
+	// total length of runs in other directions must be similar to the row
+	similar := func(n int) bool {
+		return math.Abs(float64(n-total)) < float64(total)*0.4
+	}
+
+	offsetY, vertical, ok := m.crossCheck(x, y, 0, 1, maxCount)
+	if !ok || !similar(vertical) {
+		return patternCandidate{}, false
+	}
+	cy := float64(y) + offsetY
+	offsetX, horizontal, ok := m.crossCheck(x, int(cy+0.5), 1, 0, maxCount)
+	if !ok || !similar(horizontal) {
+		return patternCandidate{}, false
+	}
+	cx := float64(x) + offsetX
+	// length of diagonal runs is not checked, because it depends on rotation of the symbol
+	if _, _, ok := m.crossCheck(int(cx+0.5), int(cy+0.5), 1, 1, maxCount); !ok {
+		return patternCandidate{}, false
+	}
+
+	return patternCandidate{
+		point:      point{x: cx + 0.5, y: cy + 0.5},
+		moduleSize: float64(vertical+horizontal) / 2 / finderPatternSize,
+		count:      1,
+	}, true
+}
+
+// mergeCandidate adds c to candidates, or averages it with a candidate at the same position
+func mergeCandidate(candidates []patternCandidate, c patternCandidate) []patternCandidate {
+	for i, e := range candidates {
+		if math.Abs(e.x-c.x) > e.moduleSize || math.Abs(e.y-c.y) > e.moduleSize {
+			continue
+		}
+		if d := math.Abs(e.moduleSize - c.moduleSize); d > 1 && d > e.moduleSize {
+			continue
+		}
+		n := float64(e.count)
+		candidates[i] = patternCandidate{
+			point: point{
+				x: (e.x*n + c.x) / (n + 1),
+				y: (e.y*n + c.y) / (n + 1),
+			},
+			moduleSize: (e.moduleSize*n + c.moduleSize) / (n + 1),
+			count:      e.count + 1,
+		}
+		return candidates
+	}
+	return append(candidates, c)
+}
+
+// findAlignmentPatterns finds alignment patterns around (estimated) within allowance modules
+// only the light, dark and light runs of 1:1:1 across the center are checked,
+// because the outer dark modules of the pattern are often connected to dark modules around it
+// it returns candidates in order of distance from the estimated center
+func (m *bitMatrix) findAlignmentPatterns(estimated point, moduleSize float64, allowance float64) []point {
+	radius := allowance * moduleSize
+	x0 := int(math.Max(0, estimated.x-radius))
+	x1 := int(math.Min(float64(m.width), estimated.x+radius))
+	y0 := int(math.Max(0, estimated.y-radius))
+	y1 := int(math.Min(float64(m.height), estimated.y+radius))
+
+	var candidates []patternCandidate
+	for y := y0; y < y1; y++ {
+		// runs of the row, and runs[i] is dark if runs[0] is dark and i is even
+		var runs []int
+		firstDark := m.get(x0, y)
+		for x := x0; x < x1; x++ {
+			if x == x0 || m.get(x, y) != m.get(x-1, y) {
+				runs = append(runs, 0)
+			}
+			runs[len(runs)-1]++
+		}
+
+		end := x0
+		for i, n := range runs {
+			end += n
+			dark := (i%2 == 0) == firstDark
+			// the center run must have light runs on both sides, and they must be surrounded by dark runs
+			if !dark || i < 2 || i+2 >= len(runs) {
+				continue
+			}
+			if !similarRuns(runs[i-1], n, runs[i+1], moduleSize) {
+				continue
+			}
+
+			c, ok := m.checkAlignment(end-n+n/2, y, moduleSize)
+			if !ok {
+				continue
+			}
+			candidates = mergeCandidate(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distance(candidates[i].point, estimated) < distance(candidates[j].point, estimated)
+	})
+	points := make([]point, len(candidates))
+	for i, c := range candidates {
+		points[i] = c.point
+	}
+	return points
+}
+
+// similarRuns returns true if lengths of 3 runs are similar, and they are about a module
+// module size in the direction of runs can be larger than moduleSize up to sqrt(2) times if the symbol is rotated
+func similarRuns(a, b, c int, moduleSize float64) bool {
+	mean := float64(a+b+c) / 3
+	if mean < moduleSize/2 || mean > moduleSize*2 {
+		return false
+	}
+	for _, n := range []int{a, b, c} {
+		if math.Abs(float64(n)-mean) >= mean/2 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAlignment checks runs through (x, y) vertically and horizontally, and returns the refined center
+func (m *bitMatrix) checkAlignment(x, y int, moduleSize float64) (patternCandidate, bool) {
+	offsetY, ok := m.crossCheckAlignment(x, y, 0, 1, moduleSize)
+	if !ok {
+		return patternCandidate{}, false
+	}
+	cy := float64(y) + offsetY
+	offsetX, ok := m.crossCheckAlignment(x, int(cy+0.5), 1, 0, moduleSize)
+	if !ok {
+		return patternCandidate{}, false
+	}
+
+	return patternCandidate{
+		point:      point{x: float64(x) + offsetX + 0.5, y: cy + 0.5},
+		moduleSize: moduleSize,
+		count:      1,
+	}, true
+}
+
+// crossCheckAlignment checks light, dark and light runs through (x, y) in the direction (dx, dy)
+// and returns offset of the center from (x, y) in steps
+func (m *bitMatrix) crossCheckAlignment(x, y, dx, dy int, moduleSize float64) (float64, bool) {
+	if !m.get(x, y) {
+		return 0, false
+	}
+	limit := int(2 * moduleSize)
+
+	// dark run is counted from the center, and light run must be followed by dark module
+	runs := func(sign int) (int, int, bool) {
+		dark, light := 0, 0
+		i := 0
+		for m.get(x+sign*i*dx, y+sign*i*dy) && dark <= limit {
+			dark++
+			i++
+		}
+		for m.inside(x+sign*i*dx, y+sign*i*dy) && !m.get(x+sign*i*dx, y+sign*i*dy) && light <= limit {
+			light++
+			i++
+		}
+		return dark, light, m.get(x+sign*i*dx, y+sign*i*dy)
+	}
+
+	backwardDark, backwardLight, ok := runs(-1)
+	if !ok {
+		return 0, false
+	}
+	forwardDark, forwardLight, ok := runs(1)
+	if !ok {
+		return 0, false
+	}
+
+	// (x, y) is counted in both directions
+	dark := backwardDark + forwardDark - 1
+	if !similarRuns(backwardLight, dark, forwardLight, moduleSize) {
+		return 0, false
+	}
+	return float64(forwardDark-backwardDark) / 2, true
+}
+
+// runLength returns length of dark, light and dark runs from (from) toward (to) by Bresenham's line algorithm
+// it returns NaN if the runs are not found
+func (m *bitMatrix) runLength(from, to point) float64 {
+	fromX, fromY := int(from.x), int(from.y)
+	toX, toY := int(to.x), int(to.y)
+
+	steep := math.Abs(float64(toY-fromY)) > math.Abs(float64(toX-fromX))
+	if steep {
+		fromX, fromY = fromY, fromX
+		toX, toY = toY, toX
+	}
+
+	dx := toX - fromX
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := toY - fromY
+	if dy < 0 {
+		dy = -dy
+	}
+	xStep, yStep := 1, 1
+	if fromX > toX {
+		xStep = -1
+	}
+	if fromY > toY {
+		yStep = -1
+	}
+
+	state := 0
+	e := -dx / 2
+	y := fromY
+	for x := fromX; x != toX+xStep; x += xStep {
+		realX, realY := x, y
+		if steep {
+			realX, realY = y, x
+		}
+		// state 0 and 2 are dark, and state 1 is light
+		if (state == 1) == m.get(realX, realY) {
+			if state == 2 {
+				return math.Hypot(float64(x-fromX), float64(y-fromY))
+			}
+			state++
+		}
+
+		e += dy
+		if e > 0 {
+			if y == toY {
+				break
+			}
+			y += yStep
+			e -= dx
+		}
+	}
+	return math.NaN()
+}
+
+// runLengthBothWays returns length of runs from the center of finder pattern toward to and the opposite direction
+// it is about 7 modules because runs are from the outer dark module to the outer dark module
+func (m *bitMatrix) runLengthBothWays(from, to point) float64 {
+	forward := m.runLength(from, to)
+
+	// opposite point is clipped in the image
+	opposite := point{x: 2*from.x - to.x, y: 2*from.y - to.y}
+	scale := 1.0
+	if opposite.x < 0 {
+		scale = math.Min(scale, from.x/(from.x-opposite.x))
+	} else if opposite.x >= float64(m.width) {
+		scale = math.Min(scale, (float64(m.width)-1-from.x)/(opposite.x-from.x))
+	}
+	if opposite.y < 0 {
+		scale = math.Min(scale, from.y/(from.y-opposite.y))
+	} else if opposite.y >= float64(m.height) {
+		scale = math.Min(scale, (float64(m.height)-1-from.y)/(opposite.y-from.y))
+	}
+	opposite = point{x: from.x + (opposite.x-from.x)*scale, y: from.y + (opposite.y-from.y)*scale}
+
+	// the center pixel is counted twice
+	return forward + m.runLength(from, opposite) - 1
+}
+
+// estimateModuleSize returns module size between two finder patterns
+func (m *bitMatrix) estimateModuleSize(a, b patternCandidate) float64 {
+	ab := m.runLengthBothWays(a.point, b.point)
+	ba := m.runLengthBothWays(b.point, a.point)
+	switch {
+	case math.IsNaN(ab) && math.IsNaN(ba):
+		return (a.moduleSize + b.moduleSize) / 2
+	case math.IsNaN(ab):
+		return ba / finderPatternSize
+	case math.IsNaN(ba):
+		return ab / finderPatternSize
+	}
+	return (ab + ba) / 2 / finderPatternSize
+}
+
+const (
+	// maxFinderCandidates is number of finder pattern candidates which are combined into triples
+	// it allows about 30 symbols in an image
+	maxFinderCandidates = 90
+
+	// maxFinderDistortion limits differences of sizes and distances of finder patterns in a triple
+	maxFinderDistortion = 2.0
+
+	// maxFinderCosine limits angle at the top left finder pattern, and it is cos 53 degrees
+	maxFinderCosine = 0.6
+
+	// maxAlignmentCandidates is max number of alignment pattern candidates to be compared
+	maxAlignmentCandidates = 5
+)
+
+// finderTriple is three finder patterns which may be corners of a symbol
+type finderTriple struct {
+	topLeft    patternCandidate
+	topRight   patternCandidate
+	bottomLeft patternCandidate
+
+	// score is lower for triples which are more like a square
+	score float64
+}
+
+// finderTriples returns combinations of finder patterns which can be a symbol in order of score
+func finderTriples(patterns []patternCandidate) []finderTriple {
+	if len(patterns) > maxFinderCandidates {
+		patterns = patterns[:maxFinderCandidates]
+	}
+
+	var triples []finderTriple
+	for i := 0; i < len(patterns); i++ {
+		for j := i + 1; j < len(patterns); j++ {
+			for k := j + 1; k < len(patterns); k++ {
+				if t, ok := newFinderTriple(patterns[i], patterns[j], patterns[k]); ok {
+					triples = append(triples, t)
+				}
+			}
+		}
+	}
+	sort.SliceStable(triples, func(i, j int) bool {
+		return triples[i].score < triples[j].score
+	})
+	return triples
+}
+
+// newFinderTriple orders three finder patterns as top left, top right and bottom left
+// the top left pattern is the opposite of the longest side, and the others are ordered clockwise in image coordinates
+func newFinderTriple(a, b, c patternCandidate) (finderTriple, bool) {
+	ab, bc, ca := distance(a.point, b.point), distance(b.point, c.point), distance(c.point, a.point)
+	var t finderTriple
+	switch {
+	case ab >= bc && ab >= ca:
+		t = finderTriple{topLeft: c, topRight: a, bottomLeft: b}
+	case bc >= ab && bc >= ca:
+		t = finderTriple{topLeft: a, topRight: b, bottomLeft: c}
+	default:
+		t = finderTriple{topLeft: b, topRight: c, bottomLeft: a}
+	}
+
+	tl, tr, bl := t.topLeft.point, t.topRight.point, t.bottomLeft.point
+	cross := (tr.x-tl.x)*(bl.y-tl.y) - (tr.y-tl.y)*(bl.x-tl.x)
+	if cross < 0 {
+		t.topRight, t.bottomLeft = t.bottomLeft, t.topRight
+		tr, bl = bl, tr
+	}
+
+	minSize := math.Min(a.moduleSize, math.Min(b.moduleSize, c.moduleSize))
+	maxSize := math.Max(a.moduleSize, math.Max(b.moduleSize, c.moduleSize))
+	if maxSize > minSize*maxFinderDistortion {
+		return finderTriple{}, false
+	}
+
+	top, left := distance(tl, tr), distance(tl, bl)
+	// centers of finder patterns are 14 modules apart at least, and module size can be measured larger in rotated symbols
+	if math.Min(top, left) < 7*maxSize || math.Max(top, left) > math.Min(top, left)*maxFinderDistortion {
+		return finderTriple{}, false
+	}
+
+	cosine := ((tr.x-tl.x)*(bl.x-tl.x) + (tr.y-tl.y)*(bl.y-tl.y)) / (top * left)
+	if math.Abs(cosine) > maxFinderCosine {
+		return finderTriple{}, false
+	}
+
+	t.score = math.Abs(top-left)/math.Max(top, left) + math.Abs(cosine) + (maxSize-minSize)/maxSize
+	return t, true
+}
+
+// decodeTriple samples modules of the symbol whose finder patterns are t, and decodes them
+// it also returns corners of the symbol in the image in order of top left, top right, bottom right and bottom left
+// dimension estimated from module size may be wrong, so that the nearest sizes are also tried
+func (m *bitMatrix) decodeTriple(t finderTriple) (decodedSymbol, [4]point, error) {
+	moduleSize := (m.estimateModuleSize(t.topLeft, t.topRight) + m.estimateModuleSize(t.topLeft, t.bottomLeft)) / 2
+	if moduleSize < 1 {
+		return decodedSymbol{}, [4]point{}, fmt.Errorf("module size %.2f is too small", moduleSize)
+	}
+
+	top := distance(t.topLeft.point, t.topRight.point) / moduleSize
+	left := distance(t.topLeft.point, t.bottomLeft.point) / moduleSize
+	estimated := int(math.Round((top+left)/2)) + finderPatternSize
+
+	sizes := candidateSymbolSizes(estimated)
+	// version information is next to finder patterns, so that it can be read even if estimated size is a little wrong
+	if grid, _, ok := m.sampleSymbol(t, sizes[0], moduleSize); ok && sizes[0] >= symbolSize(minVersionInfoVersion) {
+		if v, err := readVersionInfo(grid); err == nil && symbolSize(v) != sizes[0] {
+			sizes = append([]int{symbolSize(v)}, sizes...)
+		}
+	}
+
+	err := fmt.Errorf("symbol size %d is not a size of QR code", estimated)
+	for _, size := range sizes {
+		grid, transform, ok := m.sampleSymbol(t, size, moduleSize)
+		if !ok {
+			continue
+		}
+
+		var s decodedSymbol
+		s, err = decodeGrid(grid)
+		if err == nil {
+			n := float64(size)
+			corners := [4]point{
+				transform.transform(point{x: 0, y: 0}),
+				transform.transform(point{x: n, y: 0}),
+				transform.transform(point{x: n, y: n}),
+				transform.transform(point{x: 0, y: n}),
+			}
+			return s, corners, nil
+		}
+	}
+	return decodedSymbol{}, [4]point{}, err
+}
+
+// candidateSymbolSizes returns sizes of QR code near estimated size in order of distance
+// sizes of version 7 or higher are verified by version information, so that farther sizes are tried
+func candidateSymbolSizes(estimated int) []int {
+	version := int(math.Round(float64(estimated-symbolSize(minVersion))/4)) + minVersion
+	if version < minVersion {
+		version = minVersion
+	}
+	if version > maxVersion {
+		version = maxVersion
+	}
+
+	diffs := []int{0, 1, -1}
+	if version >= minVersionInfoVersion {
+		diffs = append(diffs, 2, -2)
+	}
+
+	var sizes []int
+	for _, d := range diffs {
+		if v := version + d; minVersion <= v && v <= maxVersion {
+			sizes = append(sizes, symbolSize(v))
+		}
+	}
+	return sizes
+}
+
+// sampleSymbol returns modules of the symbol whose size is size, and transform from module coordinates to the image
+// module coordinates are mapped to the image by perspective transform from centers of finder patterns and alignment pattern
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 12
+func (m *bitMatrix) sampleSymbol(t finderTriple, size int, moduleSize float64) ([][]bool, perspectiveTransform, bool) {
+	tl, tr, bl := t.topLeft.point, t.topRight.point, t.bottomLeft.point
+	// bottom right corner is estimated as parallelogram
+	br := point{x: tr.x - tl.x + bl.x, y: tr.y - tl.y + bl.y}
+
+	center := float64(finderPatternSize) / 2
+	far := float64(size) - center
+	src := [4]point{{center, center}, {far, center}, {center, far}, {far, far}}
+	dst := [4]point{tl, tr, bl, br}
+
+	transform, ok := newPerspectiveTransform(src, dst)
+	if !ok {
+		return nil, perspectiveTransform{}, false
+	}
+
+	// the bottom right alignment pattern is 3 modules inside from the centers of finder patterns
+	// the candidate whose sampled pattern matches the alignment pattern best is used
+	if size > symbolSize(minVersion) {
+		correction := 1 - 3/(far-center)
+		estimated := point{x: tl.x + correction*(br.x-tl.x), y: tl.y + correction*(br.y-tl.y)}
+		best := m.alignmentMismatches(transform, size)
+		for allowance := 4.0; allowance <= 16 && best > 0; allowance *= 2 {
+			candidates := m.findAlignmentPatterns(estimated, moduleSize, allowance)
+			if len(candidates) > maxAlignmentCandidates {
+				candidates = candidates[:maxAlignmentCandidates]
+			}
+			for _, c := range candidates {
+				src[3], dst[3] = point{x: far - 3, y: far - 3}, c
+				candidate, ok := newPerspectiveTransform(src, dst)
+				if !ok {
+					continue
+				}
+				if n := m.alignmentMismatches(candidate, size); n < best {
+					transform, best = candidate, n
+				}
+			}
+		}
+	}
+
+	grid := make([][]bool, size)
+	for y := range grid {
+		grid[y] = make([]bool, size)
+		for x := range grid[y] {
+			p, ok := m.sample(transform, x, y)
+			if !ok {
+				return nil, perspectiveTransform{}, false
+			}
+			grid[y][x] = p
+		}
+	}
+	return grid, transform, true
+}
+
+// sample returns color of module (x, y) mapped by transform
+// it returns false if the module is out of the image except for rounding errors
+func (m *bitMatrix) sample(transform perspectiveTransform, x, y int) (bool, bool) {
+	p := transform.transform(point{x: float64(x) + 0.5, y: float64(y) + 0.5})
+	px, py := int(math.Floor(p.x)), int(math.Floor(p.y))
+	if px < -1 || py < -1 || px > m.width || py > m.height {
+		return false, false
+	}
+	return m.get(px, py), true
+}
+
+// alignmentMismatches returns number of modules of the bottom right alignment pattern
+// which are different from the sampled modules
+func (m *bitMatrix) alignmentMismatches(transform perspectiveTransform, size int) int {
+	// center of the pattern is 7 modules inside from the bottom right corner
+	origin := size - finderPatternSize - alignmentPatternSize/2
+	mismatches := 0
+	for dy, row := range alignmentPattern {
+		for dx, dark := range row {
+			p, ok := m.sample(transform, origin+dx, origin+dy)
+			if !ok || p != dark {
+				mismatches++
+			}
+		}
+	}
+	return mismatches
+}
+
+// contains returns true if p is inside the convex quadrilateral whose corners are in clockwise order
+func contains(corners [4]point, p point) bool {
+	for i := range corners {
+		a, b := corners[i], corners[(i+1)%len(corners)]
+		// p must be on the right side of each edge in image coordinates
+		if (b.x-a.x)*(p.y-a.y)-(b.y-a.y)*(p.x-a.x) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,250 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// photo renders img into width x height image as if it is taken by a camera
+// corners are positions of top left, top right, bottom left and bottom right corners of img,
+// shade is brightness of the background light from 0 to 1 in x direction, and noise is amplitude of random noise
+func photo(img image.Image, width, height int, corners [4]point, shade float64, noise int) *image.Gray {
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	transform, _ := newPerspectiveTransform(corners, [4]point{{0, 0}, {w, 0}, {0, h}, {w, h}})
+
+	lum, _, _ := luminances(img)
+	at := func(x, y int) float64 {
+		if x < 0 || y < 0 || x >= b.Dx() || y >= b.Dy() {
+			return 230
+		}
+		return float64(lum[y*b.Dx()+x])
+	}
+
+	r := rand.New(rand.NewSource(1))
+	dst := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// bilinear interpolation
+			p := transform.transform(point{x: float64(x) + 0.5, y: float64(y) + 0.5})
+			fx, fy := p.x-0.5, p.y-0.5
+			x0, y0 := int(math.Floor(fx)), int(math.Floor(fy))
+			ax, ay := fx-float64(x0), fy-float64(y0)
+			v := at(x0, y0)*(1-ax)*(1-ay) + at(x0+1, y0)*ax*(1-ay) + at(x0, y0+1)*(1-ax)*ay + at(x0+1, y0+1)*ax*ay
+
+			v *= 1 - shade*float64(x)/float64(width)
+			if noise > 0 {
+				v += float64(r.Intn(2*noise+1) - noise)
+			}
+			dst.SetGray(x, y, color.Gray{Y: uint8(math.Max(0, math.Min(255, v)))})
+		}
+	}
+	return dst
+}
+
+func jpegImage(t *testing.T, img image.Image, quality int) image.Image {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+	decoded, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+	return decoded
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		ecl     ErrorCorrectionLevel
+		corners [4]point
+		shade   float64
+		noise   int
+		jpeg    bool
+	}{
+		{
+			name:    "front",
+			content: "Hello, World",
+			ecl:     ECL_Medium,
+			corners: [4]point{{50, 50}, {350, 50}, {50, 350}, {350, 350}},
+		},
+		{
+			name:    "rotated 90 degrees",
+			content: "Hello, World",
+			ecl:     ECL_Medium,
+			corners: [4]point{{350, 50}, {350, 350}, {50, 50}, {50, 350}},
+		},
+		{
+			name:    "rotated 30 degrees",
+			content: "WAREHOUSE-A/SHELF-12/BIN-0042",
+			ecl:     ECL_Low,
+			corners: [4]point{{120, 30}, {380, 180}, {-30, 290}, {230, 440}},
+		},
+		{
+			name:    "perspective",
+			content: strings.Repeat("PALLET 4711 ", 6),
+			ecl:     ECL_High,
+			corners: [4]point{{80, 60}, {420, 100}, {40, 420}, {440, 380}},
+		},
+		{
+			name:    "version 10 with shade and noise",
+			content: strings.Repeat("https://example.com/label?id=0123456789 ", 5),
+			ecl:     ECL_Medium,
+			corners: [4]point{{40, 30}, {560, 60}, {30, 570}, {580, 550}},
+			shade:   0.6,
+			noise:   20,
+		},
+		{
+			name:    "JPEG",
+			content: "ORDER 20261017-0001",
+			ecl:     ECL_Low,
+			corners: [4]point{{60, 40}, {330, 70}, {50, 330}, {320, 350}},
+			shade:   0.3,
+			noise:   10,
+			jpeg:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := New(test.ecl, test.content)
+			if err != nil {
+				t.Fatalf("error: %v\n", err)
+			}
+
+			var img image.Image = photo(q.Image(400), 600, 600, test.corners, test.shade, test.noise)
+			if test.jpeg {
+				img = jpegImage(t, img, 75)
+			}
+
+			got, err := Decode(img)
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if got != test.content {
+				t.Errorf("expected %q, got %q\n", test.content, got)
+			}
+		})
+	}
+}
+
+func TestDecodeReader(t *testing.T) {
+	content := "Hello, World"
+	q, err := New(ECL_High, content)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+	b, err := q.PNG(300)
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+
+	got, err := DecodeReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+	if got != content {
+		t.Errorf("expected %q, got %q\n", content, got)
+	}
+}
+
+func TestDecodeNotFound(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 200, 200))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7 % 256)
+	}
+	if _, err := Decode(img); err == nil {
+		t.Errorf("expected error, but got nil\n")
+	}
+}
+
+func TestDecodeAll(t *testing.T) {
+	codes := []struct {
+		content string
+		ecl     ErrorCorrectionLevel
+		size    int
+		at      image.Point
+	}{
+		{content: "MANIFEST 0042", ecl: ECL_Medium, size: 290, at: image.Pt(20, 20)},
+		{content: strings.Repeat("CONSIGNEE ", 5), ecl: ECL_Low, size: 369, at: image.Pt(330, 40)},
+		{content: strings.Repeat("https://example.com/parcel/0123456789 ", 3), ecl: ECL_High, size: 318, at: image.Pt(40, 360)},
+	}
+
+	page := image.NewGray(image.Rect(0, 0, 720, 720))
+	draw.Draw(page, page.Bounds(), image.White, image.Point{}, draw.Src)
+	type expected struct {
+		version int
+		ecl     ErrorCorrectionLevel
+		corners [4]point
+		module  float64
+	}
+	// page is rendered with a little perspective
+	pageCorners := [4]point{{30, 20}, {770, 40}, {20, 780}, {760, 770}}
+	toPhoto, _ := newPerspectiveTransform([4]point{{0, 0}, {720, 0}, {0, 720}, {720, 720}}, pageCorners)
+	expects := map[string]expected{}
+	for _, c := range codes {
+		q, err := New(c.ecl, c.content)
+		if err != nil {
+			t.Fatalf("error: %v\n", err)
+		}
+		img := q.Image(c.size)
+		draw.Draw(page, img.Bounds().Add(c.at), img, image.Point{}, draw.Src)
+
+		// corners of the symbol without quiet zone
+		module := float64(c.size) / float64(q.width+2*q.quietZone)
+		min := float64(q.quietZone) * module
+		max := float64(q.quietZone+q.width) * module
+		var corners [4]point
+		for i, p := range []point{{min, min}, {max, min}, {max, max}, {min, max}} {
+			corners[i] = toPhoto.transform(point{x: p.x + float64(c.at.X), y: p.y + float64(c.at.Y)})
+		}
+		expects[c.content] = expected{version: q.Version(), ecl: q.ECL(), corners: corners, module: module}
+	}
+
+	results, err := DecodeAll(photo(page, 800, 800, pageCorners, 0.3, 10))
+	if err != nil {
+		t.Fatalf("error: %v\n", err)
+	}
+	if len(results) != len(codes) {
+		t.Fatalf("expected %d codes, got %d\n", len(codes), len(results))
+	}
+	for _, r := range results {
+		e, ok := expects[r.Content]
+		if !ok {
+			t.Errorf("unexpected content %q\n", r.Content)
+			continue
+		}
+		delete(expects, r.Content)
+
+		if r.Version != e.version {
+			t.Errorf("expected version %d, got %d\n", e.version, r.Version)
+		}
+		if r.ECL != e.ecl {
+			t.Errorf("expected ecl %d, got %d\n", e.ecl, r.ECL)
+		}
+		for i, c := range r.Corners {
+			// corners are extrapolated from centers of patterns, so that they can be a little off
+			if math.Abs(float64(c.X)-e.corners[i].x) > e.module || math.Abs(float64(c.Y)-e.corners[i].y) > e.module {
+				t.Errorf("expected corner %d at %.1f, got %v\n", i, e.corners[i], c)
+			}
+		}
+	}
+}
+
+func TestDecodeAllNotFound(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 200, 200))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	if _, err := DecodeAll(img); err == nil {
+		t.Errorf("expected error, but got nil\n")
+	}
+}
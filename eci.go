@@ -0,0 +1,169 @@
+package qrcode
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/ksrnnb/qrcode/bitset"
+	"github.com/ksrnnb/qrcode/charset"
+)
+
+// ECI assignment numbers
+// reference: AIM ECI Part 3: Register
+const (
+	ECI_ISO8859_1 = 3
+	ECI_ShiftJIS  = 20
+	ECI_UTF8      = 26
+
+	maxECIAssignment = 999999
+)
+
+// ECISegment returns segment which declares the character set of following segments by ECI assignment number
+func ECISegment(assignment int) Segment {
+	return Segment{Mode: ECI, Data: strconv.Itoa(assignment)}
+}
+
+// eciAssignment returns ECI assignment number of data of ECI segment
+func eciAssignment(data string) (int, error) {
+	assignment, err := strconv.Atoi(data)
+	if err != nil || assignment < 0 || assignment > maxECIAssignment {
+		return 0, fmt.Errorf("ECI assignment number must be 0 to %d, but got %q", maxECIAssignment, data)
+	}
+	return assignment, nil
+}
+
+// eciDesignatorBits returns bit length of ECI designator
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 4
+func eciDesignatorBits(assignment int) int {
+	switch {
+	case assignment <= 127:
+		return 8
+	case assignment <= 16383:
+		return 16
+	default:
+		return 24
+	}
+}
+
+// addECIDesignator adds ECI designator
+// 0-127 => 0bbbbbbb, 128-16383 => 10bbbbbb bbbbbbbb, 16384-999999 => 110bbbbb bbbbbbbb bbbbbbbb
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.4.2.2
+func addECIDesignator(bs *bitset.BitSet, assignment int) {
+	switch eciDesignatorBits(assignment) {
+	case 8:
+		bs.SetInt(assignment, 8)
+	case 16:
+		bs.SetInt(0b10, 2)
+		bs.SetInt(assignment, 14)
+	default:
+		bs.SetInt(0b110, 3)
+		bs.SetInt(assignment, 21)
+	}
+}
+
+// eciCharset is a character set which can be declared by ECI
+type eciCharset struct {
+	assignment int
+	encoder    byteEncoder
+}
+
+// transcodeCharsets shows character sets which are tried to make 8 bits byte segments shorter than UTF-8
+var transcodeCharsets = []eciCharset{
+	{assignment: ECI_ISO8859_1, encoder: iso8859Encoder(1)},
+	{assignment: 4, encoder: iso8859Encoder(2)},
+	{assignment: 5, encoder: iso8859Encoder(3)},
+	{assignment: 6, encoder: iso8859Encoder(4)},
+	{assignment: 7, encoder: iso8859Encoder(5)},
+	{assignment: 8, encoder: iso8859Encoder(6)},
+	{assignment: 9, encoder: iso8859Encoder(7)},
+	{assignment: 10, encoder: iso8859Encoder(8)},
+	{assignment: 11, encoder: iso8859Encoder(9)},
+	{assignment: 12, encoder: iso8859Encoder(10)},
+	{assignment: 13, encoder: iso8859Encoder(11)},
+	{assignment: 15, encoder: iso8859Encoder(13)},
+	{assignment: 16, encoder: iso8859Encoder(14)},
+	{assignment: 17, encoder: iso8859Encoder(15)},
+	{assignment: 18, encoder: iso8859Encoder(16)},
+	{assignment: ECI_ShiftJIS, encoder: encodeShiftJIS},
+}
+
+// iso8859Encoder returns byteEncoder which converts a character to the part of ISO/IEC 8859
+func iso8859Encoder(part int) byteEncoder {
+	return func(c string) ([]byte, bool) {
+		r, size := utf8.DecodeRuneInString(c)
+		if r == utf8.RuneError && size <= 1 {
+			return nil, false
+		}
+		b, ok := charset.ISO8859(part, r)
+		if !ok {
+			return nil, false
+		}
+		return []byte{b}, true
+	}
+}
+
+// encodeShiftJIS converts a character to Shift_JIS
+func encodeShiftJIS(c string) ([]byte, bool) {
+	r, size := utf8.DecodeRuneInString(c)
+	if r == utf8.RuneError && size <= 1 {
+		return nil, false
+	}
+	return charset.ShiftJISBytes(r)
+}
+
+// buildSegments returns segments of src for the version
+func buildSegments(src string, version int, opts Options) []Segment {
+	fnc1, useFNC1 := fnc1Segment(opts)
+	header := qrHeaderBits(version, opts.Hanzi)
+	segments := optimizeSymbolSegments(src, header, encodeUTF8, useFNC1)
+
+	// ASCII characters are interpreted in the same way without ECI
+	if opts.Transcode && !isASCII(src) {
+		// UTF-8 is used if any other character set does not make segments shorter
+		best := withECI(segments, ECI_UTF8)
+		for _, cs := range transcodeCharsets {
+			s := optimizeSymbolSegments(src, header, cs.encoder, useFNC1)
+			if s == nil {
+				continue
+			}
+			s = withECI(s, cs.assignment)
+			if segmentsBitLength(s, version) < segmentsBitLength(best, version) {
+				best = s
+			}
+		}
+		segments = best
+	} else if opts.UTF8ECI {
+		segments = withECI(segments, ECI_UTF8)
+	}
+
+	if useFNC1 {
+		return withFNC1(segments, fnc1)
+	}
+	return segments
+}
+
+// isASCII returns true if src has only ASCII characters
+func isASCII(src string) bool {
+	for i := 0; i < len(src); i++ {
+		if src[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// withECI returns segments which have ECI segment before the first 8 bits byte segment
+// ECI is effective until the end of the symbol, so it is added only once
+func withECI(segments []Segment, assignment int) []Segment {
+	for i, s := range segments {
+		if s.Mode != EightBits {
+			continue
+		}
+		result := make([]Segment, 0, len(segments)+1)
+		result = append(result, segments[:i]...)
+		result = append(result, ECISegment(assignment))
+		return append(result, segments[i:]...)
+	}
+	return segments
+}
@@ -0,0 +1,188 @@
+package qrcode
+
+import (
+	"testing"
+
+	"github.com/ksrnnb/qrcode/bitset"
+)
+
+func TestAddECIDesignator(t *testing.T) {
+	tests := []struct {
+		name       string
+		assignment int
+		want       int
+		wantLen    int
+	}{
+		{
+			name:       "1 byte",
+			assignment: ECI_UTF8,
+			want:       0b00011010,
+			wantLen:    8,
+		},
+		{
+			name:       "2 bytes",
+			assignment: 1000,
+			want:       0b10_00001111101000,
+			wantLen:    16,
+		},
+		{
+			name:       "3 bytes",
+			assignment: 100000,
+			want:       0b110_000011000011010100000,
+			wantLen:    24,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bs := bitset.NewBitSet(0)
+			addECIDesignator(bs, test.assignment)
+			if bs.Length() != test.wantLen {
+				t.Errorf("length is expected %d, but got %d\n", test.wantLen, bs.Length())
+				return
+			}
+			for i := 0; i < test.wantLen; i++ {
+				want := (test.want>>(test.wantLen-1-i))&1 == 1
+				if bs.GetValue(i) != want {
+					t.Errorf("expected %v, but got %v at pos %d\n", want, bs.GetValue(i), i)
+				}
+			}
+		})
+	}
+}
+
+func TestWithECI(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []Segment
+		want     []Segment
+	}{
+		{
+			name: "ECI is added before the first 8 bits byte segment",
+			segments: []Segment{
+				{Mode: Numeric, Data: "123"},
+				{Mode: EightBits, Data: "café"},
+				{Mode: Kanji, Data: "点"},
+				{Mode: EightBits, Data: "abc"},
+			},
+			want: []Segment{
+				{Mode: Numeric, Data: "123"},
+				{Mode: ECI, Data: "26"},
+				{Mode: EightBits, Data: "café"},
+				{Mode: Kanji, Data: "点"},
+				{Mode: EightBits, Data: "abc"},
+			},
+		},
+		{
+			name: "ECI is not added without 8 bits byte segment",
+			segments: []Segment{
+				{Mode: Numeric, Data: "123"},
+			},
+			want: []Segment{
+				{Mode: Numeric, Data: "123"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := withECI(test.segments, ECI_UTF8)
+			if len(result) != len(test.want) {
+				t.Errorf("expected %v, got %v\n", test.want, result)
+				return
+			}
+			for i, want := range test.want {
+				if result[i] != want {
+					t.Errorf("expected %v, got %v at index %d\n", want, result[i], i)
+				}
+			}
+		})
+	}
+}
+
+func TestNewWithOptionsUTF8ECI(t *testing.T) {
+	q, err := NewWithOptions("é", Options{ECL: ECL_Medium, UTF8ECI: true})
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+		return
+	}
+
+	// 0111 00011010 0100 00000010 11000011 10101001 0000
+	want := []byte{0x71, 0xa4, 0x02, 0xc3, 0xa9, 0x00}
+	for i, w := range want {
+		if q.data.ByteAt(i) != w {
+			t.Errorf("want %#x, but got %#x at index: %d\n", w, q.data.ByteAt(i), i)
+			break
+		}
+	}
+}
+
+func TestBuildSegmentsTranscode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Segment
+	}{
+		{
+			name: "ascii does not need ECI",
+			src:  "hello",
+			want: []Segment{
+				{Mode: EightBits, Data: "hello"},
+			},
+		},
+		{
+			name: "latin-1",
+			src:  "café",
+			want: []Segment{
+				{Mode: ECI, Data: "3"},
+				{Mode: EightBits, Data: "caf\xe9"},
+			},
+		},
+		{
+			name: "cyrillic",
+			src:  "привет",
+			want: []Segment{
+				{Mode: ECI, Data: "7"},
+				{Mode: EightBits, Data: "\xdf\xe0\xd8\xd2\xd5\xe2"},
+			},
+		},
+		{
+			name: "half-width katakana",
+			src:  "ｱｲｳｴｵ",
+			want: []Segment{
+				{Mode: ECI, Data: "20"},
+				{Mode: EightBits, Data: "\xb1\xb2\xb3\xb4\xb5"},
+			},
+		},
+		{
+			name: "kanji mode does not need ECI",
+			src:  "点茗",
+			want: []Segment{
+				{Mode: Kanji, Data: "点茗"},
+			},
+		},
+		{
+			name: "falls back to UTF-8",
+			src:  "é😀",
+			want: []Segment{
+				{Mode: ECI, Data: "26"},
+				{Mode: EightBits, Data: "é😀"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := buildSegments(test.src, 1, Options{Transcode: true})
+			if len(result) != len(test.want) {
+				t.Errorf("expected %q, got %q\n", test.want, result)
+				return
+			}
+			for i, want := range test.want {
+				if result[i] != want {
+					t.Errorf("expected %q, got %q at index %d\n", want, result[i], i)
+				}
+			}
+		})
+	}
+}
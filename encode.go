@@ -1,9 +1,11 @@
 package qrcode
 
 import (
+	"strings"
 	"unicode/utf8"
 
 	"github.com/ksrnnb/qrcode/bitset"
+	"github.com/ksrnnb/qrcode/charset"
 	"github.com/ksrnnb/qrcode/reedsolomon"
 )
 
@@ -12,21 +14,112 @@ func encodeRawData(info qrInfo) (*bitset.BitSet, error) {
 	codeLength := info.countDataCodeWords * 8
 	bs := bitset.NewBitSet(codeLength)
 
-	bitCount := characterCountIndicatorBits(info.version, info.mode)
-	charCount := utf8.RuneCountInString(info.src)
+	for _, s := range info.segments {
+		bitCount := characterCountIndicatorBits(info.version, s.Mode)
+		charCount := characterCount(s.Mode, s.Data)
 
-	addModeIndicator(bs, info.mode)
-	addCharacterCountIndicator(bs, bitCount, charCount)
-	addSrcData(bs, info.src)
+		addModeIndicator(bs, s.Mode)
+		// subset indicator of hanzi mode precedes character count indicator
+		if s.Mode == Hanzi {
+			bs.SetInt(hanziSubsetGB2312, hanziSubsetBits)
+		}
+		addCharacterCountIndicator(bs, bitCount, charCount)
+		addSrcData(bs, s.Mode, s.Data)
+	}
 	addTerminator(bs)
 	addPaddingBit(bs)
 
-	rsEncoded := reedsolomon.Encode(bs, info.countErrorCordWords())
+	blocks := splitBlocks(ecBlocks[info.ecl][info.version-1], bs)
+
+	return interleaveBlocks(blocks, remainderBits[info.version-1]), nil
+}
+
+// codeBlock has data codewords and error correction codewords of an error correction block
+type codeBlock struct {
+	data []byte
+	ecc  []byte
+}
+
+// splitBlocks splits data codewords into error correction blocks, and calculates error correction codewords of each block
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.5.2
+func splitBlocks(groups []ecBlock, bs *bitset.BitSet) []codeBlock {
+	var blocks []codeBlock
+
+	pos := 0
+	for _, b := range groups {
+		ecwords := b.totalCodeWords - b.dataCodeWords
+		for i := 0; i < b.count; i++ {
+			data := bitset.NewBitSet(b.dataCodeWords * 8)
+			for j := 0; j < b.dataCodeWords; j++ {
+				data.SetByte(bs.ByteAt(pos + j))
+			}
+			pos += b.dataCodeWords
 
-	return rsEncoded, nil
+			encoded := reedsolomon.Encode(data, ecwords)
+
+			block := codeBlock{
+				data: make([]byte, b.dataCodeWords),
+				ecc:  make([]byte, ecwords),
+			}
+			for j := range block.data {
+				block.data[j] = encoded.ByteAt(j)
+			}
+			for j := range block.ecc {
+				block.ecc[j] = encoded.ByteAt(b.dataCodeWords + j)
+			}
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// interleaveBlocks arranges codewords of blocks into the final sequence and adds remainder bits
+// data codewords are arranged in order of block 1 codeword 1, block 2 codeword 1, ..., block n codeword 1, block 1 codeword 2, ...
+// and error correction codewords follow them in the same way
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.6
+func interleaveBlocks(blocks []codeBlock, remainder int) *bitset.BitSet {
+	maxData := 0
+	maxEcc := 0
+	total := 0
+	for _, b := range blocks {
+		if len(b.data) > maxData {
+			maxData = len(b.data)
+		}
+		if len(b.ecc) > maxEcc {
+			maxEcc = len(b.ecc)
+		}
+		total += len(b.data) + len(b.ecc)
+	}
+
+	result := bitset.NewBitSet(total*8 + remainder)
+
+	for i := 0; i < maxData; i++ {
+		for _, b := range blocks {
+			// blocks in the first group have one less data codeword than the second group
+			if i < len(b.data) {
+				result.SetByte(b.data[i])
+			}
+		}
+	}
+
+	for i := 0; i < maxEcc; i++ {
+		for _, b := range blocks {
+			if i < len(b.ecc) {
+				result.SetByte(b.ecc[i])
+			}
+		}
+	}
+
+	// remainder bits are 0
+	for i := 0; i < remainder; i++ {
+		result.SetBool(false)
+	}
+
+	return result
 }
 
 // characterCountIndicatorBits returns character count indicater's bit numbers
+// it returns 0 for modes which have no character count indicator such as ECI
 // reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 3
 func characterCountIndicatorBits(version int, mode ModeIndicator) int {
 	if 1 <= version && version <= 9 {
@@ -47,7 +140,7 @@ func version1To9CharacterCountIndicatorBits(mode ModeIndicator) int {
 		return 9
 	case EightBits:
 		return 8
-	case Kanji:
+	case Kanji, Hanzi:
 		return 8
 	default:
 		return 0
@@ -62,7 +155,7 @@ func version10To26CharacterCountIndicatorBits(mode ModeIndicator) int {
 		return 11
 	case EightBits:
 		return 16
-	case Kanji:
+	case Kanji, Hanzi:
 		return 10
 	default:
 		return 0
@@ -77,13 +170,22 @@ func version27To40CharacterCountIndicatorBits(mode ModeIndicator) int {
 		return 13
 	case EightBits:
 		return 16
-	case Kanji:
+	case Kanji, Hanzi:
 		return 12
 	default:
 		return 0
 	}
 }
 
+// characterCount returns number of characters in the mode
+// 8 bits byte mode counts bytes of UTF-8 sequence, and other modes count runes
+func characterCount(mode ModeIndicator, src string) int {
+	if mode == EightBits {
+		return len(src)
+	}
+	return utf8.RuneCountInString(src)
+}
+
 // addModeIndicator adds mode indicator and returns next position
 func addModeIndicator(bs *bitset.BitSet, mode ModeIndicator) {
 	bs.SetInt(int(mode), modeCharCount)
@@ -94,14 +196,150 @@ func addCharacterCountIndicator(bs *bitset.BitSet, bitCount int, charCount int)
 	bs.SetInt(charCount, bitCount)
 }
 
+// alphaNumericTable shows characters which can be encoded in alpha numeric mode
+// index of each character is its value
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 5
+const alphaNumericTable = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// isNumeric returns true if c can be encoded in numeric mode
+func isNumeric(c rune) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isAlphaNumeric returns true if c can be encoded in alpha numeric mode
+func isAlphaNumeric(c rune) bool {
+	return strings.ContainsRune(alphaNumericTable, c)
+}
+
+// isKanji returns true if c can be encoded in kanji mode
+func isKanji(c rune) bool {
+	_, ok := charset.ShiftJIS(c)
+	return ok
+}
+
+// isHanzi returns true if c can be encoded in hanzi mode
+func isHanzi(c rune) bool {
+	_, ok := charset.GB2312(c)
+	return ok
+}
+
 // addSrcData adds src data and returns next position
-func addSrcData(bs *bitset.BitSet, src string) {
-	// supports only 8 bit byte mode
+func addSrcData(bs *bitset.BitSet, mode ModeIndicator, src string) {
+	switch mode {
+	case Numeric:
+		addNumericData(bs, src)
+	case AlphaNumeric:
+		addAlphaNumericData(bs, src)
+	case Kanji:
+		addKanjiData(bs, src)
+	case Hanzi:
+		addHanziData(bs, src)
+	case ECI:
+		assignment, _ := eciAssignment(src)
+		addECIDesignator(bs, assignment)
+	case StructuredAppend:
+		// symbol sequence indicator and parity data
+		bs.SetBytes([]byte(src))
+	case FNC1First:
+		// FNC1 in first position has only mode indicator
+	case FNC1Second:
+		indicator, _ := applicationIndicator(src)
+		bs.SetInt(indicator, applicationIndicatorBits)
+	default:
+		addEightBitsData(bs, src)
+	}
+}
+
+// addNumericData adds numeric data
+// src is divided into groups of 3 digits, and each group is converted to 10 bits binary
+// if the number of digits is not a multiple of 3, the final 1 or 2 digits are converted to 4 or 7 bits binary
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.4.3
+func addNumericData(bs *bitset.BitSet, src string) {
+	// bit length for each number of digits
+	bitLengths := []int{0, 4, 7, 10}
+
+	for i := 0; i < len(src); i += 3 {
+		end := i + 3
+		if end > len(src) {
+			end = len(src)
+		}
+
+		v := 0
+		for _, c := range src[i:end] {
+			v = v*10 + int(c-'0')
+		}
+		bs.SetInt(v, bitLengths[end-i])
+	}
+}
+
+// addAlphaNumericData adds alpha numeric data
+// src is divided into groups of 2 characters, and each group is converted to 11 bits binary as (first value) * 45 + (second value)
+// if the number of characters is odd, the final character is converted to 6 bits binary
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.4.4
+func addAlphaNumericData(bs *bitset.BitSet, src string) {
+	for i := 0; i < len(src); i += 2 {
+		first := strings.IndexByte(alphaNumericTable, src[i])
+		if i+1 == len(src) {
+			bs.SetInt(first, 6)
+			break
+		}
+
+		second := strings.IndexByte(alphaNumericTable, src[i+1])
+		bs.SetInt(first*45+second, 11)
+	}
+}
+
+// addKanjiData adds kanji data
+// each character is converted to Shift_JIS double byte code, and the code is compacted to 13 bits binary
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.4.6
+func addKanjiData(bs *bitset.BitSet, src string) {
 	for _, c := range src {
-		bs.SetByte(byte(c))
+		code, _ := charset.ShiftJIS(c)
+
+		// 0x8140-0x9FFC => 0x0000-0x1EBC, 0xE040-0xEBBF => 0x1F00-0x2A7F
+		v := int(code)
+		if v <= 0x9FFC {
+			v -= 0x8140
+		} else {
+			v -= 0xC140
+		}
+
+		bs.SetInt((v>>8)*0xC0+(v&0xFF), 13)
 	}
 }
 
+const (
+	hanziSubsetBits = 4
+
+	// subset indicator of GB 2312 characters in hanzi mode
+	hanziSubsetGB2312 = 0b0001
+)
+
+// addHanziData adds hanzi data
+// each character is converted to GB 2312 (EUC-CN) double byte code, and the code is compacted to 13 bits binary
+// reference: GB/T 18284 : 2000 8.4.6
+func addHanziData(bs *bitset.BitSet, src string) {
+	for _, c := range src {
+		code, _ := charset.GB2312(c)
+
+		// 0xA1A1-0xAAFE => 0x0000-0x09FE, 0xB0A1-0xFAFE => 0x0A00-0x545D
+		v := int(code)
+		if v <= 0xAAFE {
+			v -= 0xA1A1
+		} else {
+			v -= 0xA6A1
+		}
+
+		bs.SetInt((v>>8)*0x60+(v&0xFF), 13)
+	}
+}
+
+// addEightBitsData adds 8 bits byte data
+// src is encoded as UTF-8 byte sequence
+func addEightBitsData(bs *bitset.BitSet, src string) {
+	bs.SetBytes([]byte(src))
+}
+
 // addTerminator adds 0000 padding and returns next position
 func addTerminator(bs *bitset.BitSet) {
 	if bs.Position() == bs.Length() {
@@ -136,7 +374,7 @@ func addPaddingBit(bs *bitset.BitSet) {
 		return
 	}
 
-	paddingPatterns := []int{0b11101100, 00010001}
+	paddingPatterns := []int{0b11101100, 0b00010001}
 	for i := 0; bs.Position() < bs.Length(); i++ {
 		bs.SetInt(paddingPatterns[i%2], 8)
 	}
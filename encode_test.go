@@ -19,10 +19,34 @@ func TestEncodeRawData(t *testing.T) {
 			data: "Hello, World!",
 			want: []byte{64, 212, 134, 86, 198, 198, 242, 194, 5, 118, 247, 38, 198, 66, 16, 236},
 		},
+		{
+			name: "1-M UTF-8 encode",
+			ecl:  ECL_Medium,
+			data: "café",
+			want: []byte{64, 86, 54, 22, 108, 58, 144, 236, 17, 236, 17, 236, 17, 236, 17, 236},
+		},
+		{
+			name: "1-Q alpha numeric encode",
+			ecl:  ECL_High,
+			data: "HELLO WORLD",
+			want: []byte{
+				32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236,
+				168, 72, 22, 82, 217, 54, 156, 0, 46, 15, 180, 122, 16,
+			},
+		},
+		{
+			name: "1-M numeric encode",
+			ecl:  ECL_Medium,
+			data: "01234567",
+			want: []byte{
+				16, 32, 12, 86, 97, 128, 236, 17, 236, 17, 236, 17, 236, 17, 236, 17,
+				165, 36, 212, 193, 237, 54, 199, 135, 44, 85,
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			info := newQRInfo(test.ecl, test.data)
+			info := newQRInfo(1, test.ecl, optimizeSegments(test.data, 1, encodeUTF8, false))
 			result, err := encodeRawData(info)
 			if err != nil {
 				t.Errorf("error: %v\n", err)
@@ -224,3 +248,205 @@ func TestAddZeroPadding(t *testing.T) {
 		})
 	}
 }
+
+func TestInterleaveBlocks(t *testing.T) {
+	tests := []struct {
+		name      string
+		blocks    []codeBlock
+		remainder int
+		want      []byte
+		wantLen   int
+	}{
+		{
+			name: "single block",
+			blocks: []codeBlock{
+				{data: []byte{1, 2, 3}, ecc: []byte{4, 5}},
+			},
+			want:    []byte{1, 2, 3, 4, 5},
+			wantLen: 40,
+		},
+		{
+			name: "blocks in two groups with remainder bits",
+			blocks: []codeBlock{
+				{data: []byte{11, 12}, ecc: []byte{51, 52, 53}},
+				{data: []byte{21, 22}, ecc: []byte{61, 62, 63}},
+				{data: []byte{31, 32, 33}, ecc: []byte{71, 72, 73}},
+				{data: []byte{41, 42, 43}, ecc: []byte{81, 82, 83}},
+			},
+			remainder: 7,
+			want: []byte{
+				11, 21, 31, 41, 12, 22, 32, 42, 33, 43,
+				51, 61, 71, 81, 52, 62, 72, 82, 53, 63, 73, 83,
+			},
+			wantLen: 22*8 + 7,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := interleaveBlocks(test.blocks, test.remainder)
+			if result.Length() != test.wantLen {
+				t.Errorf("length is expected %d, but got %d\n", test.wantLen, result.Length())
+				return
+			}
+			for i, want := range test.want {
+				if result.ByteAt(i) != want {
+					t.Errorf("want %d, but got %d at index: %d\n", want, result.ByteAt(i), i)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestAddNumericData(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    int
+		wantLen int
+	}{
+		{
+			name:    "multiple of 3 digits",
+			src:     "012345",
+			want:    0b0000001100_0101011001,
+			wantLen: 20,
+		},
+		{
+			name:    "final 2 digits",
+			src:     "01234567",
+			want:    0b0000001100_0101011001_1000011,
+			wantLen: 27,
+		},
+		{
+			name:    "final 1 digit",
+			src:     "0123",
+			want:    0b0000001100_0011,
+			wantLen: 14,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bs := bitset.NewBitSet(0)
+			addNumericData(bs, test.src)
+			if bs.Length() != test.wantLen {
+				t.Errorf("length is expected %d, but got %d\n", test.wantLen, bs.Length())
+				return
+			}
+			for i := 0; i < test.wantLen; i++ {
+				want := (test.want>>(test.wantLen-1-i))&1 == 1
+				if bs.GetValue(i) != want {
+					t.Errorf("expected %v, but got %v at pos %d\n", want, bs.GetValue(i), i)
+				}
+			}
+		})
+	}
+}
+
+func TestAddAlphaNumericData(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    int
+		wantLen int
+	}{
+		{
+			name:    "even number of characters",
+			src:     "AC-4",
+			want:    0b00111001110_11100111001,
+			wantLen: 22,
+		},
+		{
+			name:    "odd number of characters",
+			src:     "AC-42",
+			want:    0b00111001110_11100111001_000010,
+			wantLen: 28,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bs := bitset.NewBitSet(0)
+			addAlphaNumericData(bs, test.src)
+			if bs.Length() != test.wantLen {
+				t.Errorf("length is expected %d, but got %d\n", test.wantLen, bs.Length())
+				return
+			}
+			for i := 0; i < test.wantLen; i++ {
+				want := (test.want>>(test.wantLen-1-i))&1 == 1
+				if bs.GetValue(i) != want {
+					t.Errorf("expected %v, but got %v at pos %d\n", want, bs.GetValue(i), i)
+				}
+			}
+		})
+	}
+}
+
+func TestAddKanjiData(t *testing.T) {
+	bs := bitset.NewBitSet(0)
+	addKanjiData(bs, "点茗")
+
+	want := 0b0110110011111_1101010101010
+	wantLen := 26
+	if bs.Length() != wantLen {
+		t.Errorf("length is expected %d, but got %d\n", wantLen, bs.Length())
+		return
+	}
+	for i := 0; i < wantLen; i++ {
+		w := (want>>(wantLen-1-i))&1 == 1
+		if bs.GetValue(i) != w {
+			t.Errorf("expected %v, but got %v at pos %d\n", w, bs.GetValue(i), i)
+		}
+	}
+}
+
+func TestAddHanziData(t *testing.T) {
+	bs := bitset.NewBitSet(0)
+	// 啊 (0xB0A1) => 0x0A00 => 0x0A * 0x60 + 0x00, 中 (0xD6D0) => 0x302F => 0x30 * 0x60 + 0x2F
+	addHanziData(bs, "啊中")
+
+	want := 0b0001111000000_1001000101111
+	wantLen := 26
+	if bs.Length() != wantLen {
+		t.Errorf("length is expected %d, but got %d\n", wantLen, bs.Length())
+		return
+	}
+	for i := 0; i < wantLen; i++ {
+		w := (want>>(wantLen-1-i))&1 == 1
+		if bs.GetValue(i) != w {
+			t.Errorf("expected %v, but got %v at pos %d\n", w, bs.GetValue(i), i)
+		}
+	}
+}
+
+func TestCharacterCount(t *testing.T) {
+	tests := []struct {
+		name string
+		mode ModeIndicator
+		src  string
+		want int
+	}{
+		{
+			name: "8 bits byte mode counts bytes",
+			mode: EightBits,
+			src:  "café😀",
+			want: 9,
+		},
+		{
+			name: "kanji mode counts characters",
+			mode: Kanji,
+			src:  "点茗",
+			want: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := characterCount(test.mode, test.src)
+			if result != test.want {
+				t.Errorf("expected %d, got %d\n", test.want, result)
+			}
+		})
+	}
+}
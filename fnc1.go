@@ -0,0 +1,61 @@
+package qrcode
+
+import "fmt"
+
+const (
+	// GS (group separator) terminates variable length data fields in FNC1 mode
+	groupSeparator = '\x1d'
+
+	applicationIndicatorBits = 8
+)
+
+// FNC1FirstSegment returns segment of FNC1 in first position
+// data must be formatted according to GS1 General Specifications
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.4.8.2
+func FNC1FirstSegment() Segment {
+	return Segment{Mode: FNC1First}
+}
+
+// FNC1SecondSegment returns segment of FNC1 in second position
+// indicator is application indicator assigned by AIM, which is two digits ("00"-"99") or a single letter (a-z, A-Z)
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.4.8.3
+func FNC1SecondSegment(indicator string) Segment {
+	return Segment{Mode: FNC1Second, Data: indicator}
+}
+
+// applicationIndicator returns 8 bits value of application indicator
+// two digits are encoded as the number, and a letter is encoded as its ASCII value + 100
+func applicationIndicator(data string) (int, error) {
+	switch {
+	case len(data) == 2 && isNumeric(rune(data[0])) && isNumeric(rune(data[1])):
+		return int(data[0]-'0')*10 + int(data[1]-'0'), nil
+	case len(data) == 1 && ('a' <= data[0] && data[0] <= 'z' || 'A' <= data[0] && data[0] <= 'Z'):
+		return int(data[0]) + 100, nil
+	}
+	return 0, fmt.Errorf("application indicator must be two digits or a single letter, but got %q", data)
+}
+
+// fnc1Segment returns FNC1 segment of options, and returns false if FNC1 mode is not used
+func fnc1Segment(opts Options) (Segment, bool) {
+	if opts.GS1 {
+		return FNC1FirstSegment(), true
+	}
+	if opts.ApplicationIndicator != "" {
+		return FNC1SecondSegment(opts.ApplicationIndicator), true
+	}
+	return Segment{}, false
+}
+
+// withFNC1 returns segments which have FNC1 segment before the first data segment
+// FNC1 mode indicator follows structured append header and ECI at the beginning of the symbol
+func withFNC1(segments []Segment, fnc1 Segment) []Segment {
+	i := 0
+	for i < len(segments) && (segments[i].Mode == StructuredAppend || segments[i].Mode == ECI) {
+		i++
+	}
+
+	result := make([]Segment, 0, len(segments)+1)
+	result = append(result, segments[:i]...)
+	result = append(result, fnc1)
+	return append(result, segments[i:]...)
+}
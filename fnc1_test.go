@@ -0,0 +1,155 @@
+package qrcode
+
+import (
+	"testing"
+
+	"github.com/ksrnnb/qrcode/gs1"
+)
+
+func TestApplicationIndicator(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{name: "two digits", data: "37", want: 37},
+		{name: "lower case letter", data: "a", want: 197},
+		{name: "upper case letter", data: "Z", want: 190},
+		{name: "three digits", data: "100", wantErr: true},
+		{name: "symbol", data: "%", wantErr: true},
+		{name: "empty", data: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := applicationIndicator(test.data)
+			if (err != nil) != test.wantErr {
+				t.Errorf("expected error %v, got %v\n", test.wantErr, err)
+				return
+			}
+			if result != test.want {
+				t.Errorf("expected %d, got %d\n", test.want, result)
+			}
+		})
+	}
+}
+
+func TestBuildSegmentsFNC1(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+		want []Segment
+	}{
+		{
+			name: "GS1 fixed length fields",
+			// (01)09506000134352(17)201225
+			src:  "0109506000134352" + "17201225",
+			opts: Options{GS1: true},
+			want: []Segment{
+				{Mode: FNC1First},
+				{Mode: Numeric, Data: "010950600013435217201225"},
+			},
+		},
+		{
+			name: "GS is encoded as percent in alpha numeric mode",
+			// (10)ABC123(21)XYZ
+			src:  "10ABC123\x1d21XYZ",
+			opts: Options{GS1: true},
+			want: []Segment{
+				{Mode: FNC1First},
+				{Mode: AlphaNumeric, Data: "10ABC123%21XYZ"},
+			},
+		},
+		{
+			name: "percent is escaped in alpha numeric mode",
+			src:  "10A%B",
+			opts: Options{GS1: true},
+			want: []Segment{
+				{Mode: FNC1First},
+				{Mode: AlphaNumeric, Data: "10A%%B"},
+			},
+		},
+		{
+			name: "GS is encoded as it is in 8 bits byte mode",
+			src:  "10abc\x1d21xyz",
+			opts: Options{GS1: true},
+			want: []Segment{
+				{Mode: FNC1First},
+				{Mode: EightBits, Data: "10abc\x1d21xyz"},
+			},
+		},
+		{
+			name: "FNC1 in second position follows ECI",
+			src:  "é",
+			opts: Options{ApplicationIndicator: "37", UTF8ECI: true},
+			want: []Segment{
+				{Mode: ECI, Data: "26"},
+				{Mode: FNC1Second, Data: "37"},
+				{Mode: EightBits, Data: "é"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := buildSegments(test.src, 1, test.opts)
+			if len(result) != len(test.want) {
+				t.Errorf("expected %q, got %q\n", test.want, result)
+				return
+			}
+			for i, want := range test.want {
+				if result[i] != want {
+					t.Errorf("expected %q, got %q at index %d\n", want, result[i], i)
+				}
+			}
+		})
+	}
+}
+
+func TestNewWithOptionsFNC1Second(t *testing.T) {
+	q, err := NewWithOptions("AB", Options{ECL: ECL_Medium, ApplicationIndicator: "a"})
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+		return
+	}
+
+	// 1001 11000101 0010 000000010 00111001101 0000
+	want := []byte{0x9c, 0x52, 0x01, 0x1c, 0xd0}
+	for i, w := range want {
+		if q.data.ByteAt(i) != w {
+			t.Errorf("want %#x, but got %#x at index: %d\n", w, q.data.ByteAt(i), i)
+			break
+		}
+	}
+
+	_, err = NewWithOptions("AB", Options{GS1: true, ApplicationIndicator: "a"})
+	if err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+}
+
+func TestNewWithOptionsGS1Payload(t *testing.T) {
+	payload, err := gs1.Encode("(10)AB1(17)201225")
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+		return
+	}
+
+	q, err := NewWithOptions(payload, Options{ECL: ECL_Medium, GS1: true})
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+		return
+	}
+
+	// 0101 0010 000000110 (10 = 00000101101) (AB = 00111001101) (1% = 00001010011)
+	// 0001 0000001000 (172 = 0010101100) (012 = 0000001100) (25 = 0011001) 0000
+	want := []byte{0x52, 0x03, 0x02, 0xd3, 0x9a, 0x14, 0xc4, 0x08, 0x2b, 0x00, 0xc3, 0x20}
+	for i, w := range want {
+		if q.data.ByteAt(i) != w {
+			t.Errorf("want %#x, but got %#x at index: %d\n", w, q.data.ByteAt(i), i)
+			break
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package qrcode
 
-import "github.com/ksrnnb/qrcode/bitset"
+import (
+	"fmt"
+
+	"github.com/ksrnnb/qrcode/bitset"
+)
 
 type ErrorCorrectionLevel uint8
 
@@ -22,6 +26,11 @@ const (
 	modeCharCount = 4
 
 	formatInfoLength = 15
+
+	versionInfoLength = 18
+
+	// version information is added to version 7 or higher
+	minVersionInfoVersion = 7
 )
 
 // maskedBitSequence means masking (5, 15, 7) BCH code
@@ -61,6 +70,46 @@ var maskedBitSequence = []uint16{
 	0x2BED,
 }
 
+// versionBitSequence means (18, 6) BCH code of version 7 to 40
+// index of slice is version - 7
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table D.1
+var versionBitSequence = []uint32{
+	0x07C94,
+	0x085BC,
+	0x09A99,
+	0x0A4D3,
+	0x0BBF6,
+	0x0C762,
+	0x0D847,
+	0x0E60D,
+	0x0F928,
+	0x10B78,
+	0x1145D,
+	0x12A17,
+	0x13532,
+	0x149A6,
+	0x15683,
+	0x168C9,
+	0x177EC,
+	0x18EC4,
+	0x191E1,
+	0x1AFAB,
+	0x1B08E,
+	0x1CC1A,
+	0x1D33F,
+	0x1ED75,
+	0x1F250,
+	0x209D5,
+	0x216F0,
+	0x228BA,
+	0x2379F,
+	0x24B0B,
+	0x2542E,
+	0x26A64,
+	0x27541,
+	0x28C69,
+}
+
 type ModeIndicator uint8
 
 // reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 2
@@ -75,6 +124,84 @@ const (
 	Kanji
 )
 
+const (
+	// Extended Channel Interpretation
+	ECI ModeIndicator = 0b0111
+
+	StructuredAppend ModeIndicator = 0b0011
+
+	// FNC1 in first position for GS1 application identifiers
+	FNC1First ModeIndicator = 0b0101
+	// FNC1 in second position for application indicators assigned by AIM
+	FNC1Second ModeIndicator = 0b1001
+
+	// Hanzi mode encodes GB 2312 characters, and it is defined in GB/T 18284
+	Hanzi ModeIndicator = 0b1101
+)
+
+func (m ModeIndicator) String() string {
+	switch m {
+	case Numeric:
+		return "numeric"
+	case AlphaNumeric:
+		return "alpha numeric"
+	case EightBits:
+		return "8 bits byte"
+	case Kanji:
+		return "kanji"
+	case Hanzi:
+		return "hanzi"
+	case ECI:
+		return "ECI"
+	case StructuredAppend:
+		return "structured append"
+	case FNC1First:
+		return "FNC1 first position"
+	case FNC1Second:
+		return "FNC1 second position"
+	default:
+		return fmt.Sprintf("unknown(%04b)", uint8(m))
+	}
+}
+
+// microMaskedBitSequence means masking (5, 15, 7) BCH code of Micro QR code
+// index is symbol number (3 bits) and mask pattern (2 bits)
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table C.2
+var microMaskedBitSequence = []uint16{
+	0x4445,
+	0x4172,
+	0x4E2B,
+	0x4B1C,
+	0x55AE,
+	0x5099,
+	0x5FC0,
+	0x5AF7,
+	0x6793,
+	0x62A4,
+	0x6DFD,
+	0x68CA,
+	0x7678,
+	0x734F,
+	0x7C16,
+	0x7921,
+	0x06DE,
+	0x03E9,
+	0x0CB0,
+	0x0987,
+	0x1735,
+	0x1202,
+	0x1D5B,
+	0x186C,
+	0x2508,
+	0x203F,
+	0x2F66,
+	0x2A51,
+	0x34E3,
+	0x31D4,
+	0x3E8D,
+	0x3BBA,
+}
+
 func FormatInfo(ecl ErrorCorrectionLevel, mask uint8) *bitset.BitSet {
 	formatBitSequence := (uint8(ecl) << 3) | mask
 
@@ -88,3 +215,35 @@ func FormatInfo(ecl ErrorCorrectionLevel, mask uint8) *bitset.BitSet {
 
 	return bs
 }
+
+// MicroFormatInfo returns format information of Micro QR code
+// symbolNumber shows version and error correction level, and mask is mask pattern of Micro QR code (0-3)
+func MicroFormatInfo(symbolNumber int, mask uint8) *bitset.BitSet {
+	fi := microMaskedBitSequence[symbolNumber<<2|int(mask)]
+
+	// convert uint16 to bitset
+	bs := bitset.NewBitSet(formatInfoLength)
+	for i := formatInfoLength - 1; i >= 0; i-- {
+		bs.SetBool((fi >> i & 1) == 1)
+	}
+
+	return bs
+}
+
+// VersionInfo returns version information of version 7 or higher
+// it returns nil if version is less than 7, because version information is not needed
+func VersionInfo(version int) *bitset.BitSet {
+	if version < minVersionInfoVersion || version > maxVersion {
+		return nil
+	}
+
+	vi := versionBitSequence[version-minVersionInfoVersion]
+
+	// convert uint32 to bitset
+	bs := bitset.NewBitSet(versionInfoLength)
+	for i := versionInfoLength - 1; i >= 0; i-- {
+		bs.SetBool((vi >> i & 1) == 1)
+	}
+
+	return bs
+}
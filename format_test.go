@@ -35,3 +35,52 @@ func TestFormatInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestVersionInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		want    []bool
+	}{
+		{
+			name:    "version 6 has no version information",
+			version: 6,
+			want:    nil,
+		},
+		{
+			name:    "version 7",
+			version: 7,
+			want: []bool{
+				false, false, false, true, true, true,
+				true, true, false, false, true, false,
+				false, true, false, true, false, false,
+			},
+		},
+		{
+			name:    "version 40",
+			version: 40,
+			want: []bool{
+				true, false, true, false, false, false,
+				true, true, false, false, false, true,
+				true, false, true, false, false, true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := VersionInfo(test.version)
+			if test.want == nil {
+				if result != nil {
+					t.Errorf("expected nil, got %v\n", result)
+				}
+				return
+			}
+			for i, want := range test.want {
+				if result.GetValue(i) != want {
+					t.Errorf("expected %v, got %v at index: %d\n", want, result.GetValue(i), i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package gs1
+
+import "fmt"
+
+// characterSet is set of characters which can be used in a field
+type characterSet int
+
+const (
+	digits characterSet = iota
+
+	// GS1 AI encodable character set 82
+	cset82
+
+	// GS1 AI encodable character set 39 for component and part identifiers
+	cset39
+
+	// GS1 AI encodable character set 64 for digital signatures, which is URL and filename safe base64
+	cset64
+
+	// only "-" which indicates negative value
+	minusSign
+)
+
+// field is a part of data of application identifier
+type field struct {
+	set characterSet
+	min int
+	max int
+
+	// last digit is check digit calculated by modulo 10 of GS1
+	checkDigit bool
+
+	// data is date YYMMDD, and it may be followed by hours, minutes and seconds
+	date bool
+
+	// day of date can be 00 if only year and month are known
+	zeroDay bool
+}
+
+// n returns fixed length numeric field
+func n(length int) field {
+	return field{set: digits, min: length, max: length}
+}
+
+// nCheck returns fixed length numeric field whose last digit is check digit
+func nCheck(length int) field {
+	return field{set: digits, min: length, max: length, checkDigit: true}
+}
+
+// nVar returns variable length numeric field up to max digits
+func nVar(max int) field {
+	return field{set: digits, min: 1, max: max}
+}
+
+// nDate returns date field YYMMDD
+func nDate() field {
+	return field{set: digits, min: 6, max: 6, date: true}
+}
+
+// nDateOfMonth returns date field YYMMDD whose day can be 00
+func nDateOfMonth() field {
+	return field{set: digits, min: 6, max: 6, date: true, zeroDay: true}
+}
+
+// nDateTime returns date and time field from min to max digits, such as YYMMDDHH[MM]
+func nDateTime(min, max int) field {
+	return field{set: digits, min: min, max: max, date: true}
+}
+
+// optional returns f which can be omitted
+func optional(f field) field {
+	f.min = 0
+	return f
+}
+
+// x returns fixed length alpha numeric field
+func x(length int) field {
+	return field{set: cset82, min: length, max: length}
+}
+
+// xVar returns variable length alpha numeric field up to max characters
+func xVar(max int) field {
+	return field{set: cset82, min: 1, max: max}
+}
+
+// negative returns optional field of "-" which indicates the preceding value is negative
+func negative() field {
+	return field{set: minusSign, min: 0, max: 1}
+}
+
+// yVar returns variable length field of character set 39 up to max characters
+func yVar(max int) field {
+	return field{set: cset39, min: 1, max: max}
+}
+
+// zVar returns variable length field of character set 64 up to max characters
+func zVar(max int) field {
+	return field{set: cset64, min: 1, max: max}
+}
+
+// applicationIdentifier is format of data of application identifier
+// fields are concatenated, and only the last field can have variable length
+type applicationIdentifier struct {
+	title  string
+	fields []field
+}
+
+// predefinedLengths has the first two digits of application identifiers whose data length is predefined
+// element strings of them are not terminated by FNC1 even if another element string follows
+// reference: GS1 General Specifications 7.8.5
+var predefinedLengths = map[string]bool{
+	"00": true, "01": true, "02": true, "03": true, "04": true,
+	"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true, "18": true, "19": true, "20": true,
+	"31": true, "32": true, "33": true, "34": true, "35": true, "36": true,
+	"41": true,
+}
+
+// applicationIdentifiers has formats of application identifiers
+// reference: GS1 General Specifications 3.2
+var applicationIdentifiers = map[string]applicationIdentifier{
+	"00":   {"SSCC", []field{nCheck(18)}},
+	"01":   {"GTIN", []field{nCheck(14)}},
+	"02":   {"CONTENT", []field{nCheck(14)}},
+	"03":   {"MTO GTIN", []field{nCheck(14)}},
+	"10":   {"BATCH/LOT", []field{xVar(20)}},
+	"11":   {"PROD DATE", []field{nDateOfMonth()}},
+	"12":   {"DUE DATE", []field{nDateOfMonth()}},
+	"13":   {"PACK DATE", []field{nDateOfMonth()}},
+	"15":   {"BEST BEFORE or BEST BY", []field{nDateOfMonth()}},
+	"16":   {"SELL BY", []field{nDateOfMonth()}},
+	"17":   {"USE BY or EXPIRY", []field{nDateOfMonth()}},
+	"20":   {"VARIANT", []field{n(2)}},
+	"21":   {"SERIAL", []field{xVar(20)}},
+	"22":   {"CPV", []field{xVar(20)}},
+	"235":  {"TPX", []field{xVar(28)}},
+	"240":  {"ADDITIONAL ID", []field{xVar(30)}},
+	"241":  {"CUST. PART No.", []field{xVar(30)}},
+	"242":  {"MTO VARIANT", []field{nVar(6)}},
+	"243":  {"PCN", []field{xVar(20)}},
+	"250":  {"SECONDARY SERIAL", []field{xVar(30)}},
+	"251":  {"REF. TO SOURCE", []field{xVar(30)}},
+	"253":  {"GDTI", []field{nCheck(13), xVar(17)}},
+	"254":  {"GLN EXTENSION COMPONENT", []field{xVar(20)}},
+	"255":  {"GCN", []field{nCheck(13), nVar(12)}},
+	"30":   {"VAR. COUNT", []field{nVar(8)}},
+	"37":   {"COUNT", []field{nVar(8)}},
+	"400":  {"ORDER NUMBER", []field{xVar(30)}},
+	"401":  {"GINC", []field{xVar(30)}},
+	"402":  {"GSIN", []field{nCheck(17)}},
+	"403":  {"ROUTE", []field{xVar(30)}},
+	"410":  {"SHIP TO LOC", []field{nCheck(13)}},
+	"411":  {"BILL TO", []field{nCheck(13)}},
+	"412":  {"PURCHASE FROM", []field{nCheck(13)}},
+	"413":  {"SHIP FOR LOC", []field{nCheck(13)}},
+	"414":  {"LOC No.", []field{nCheck(13)}},
+	"415":  {"PAY TO", []field{nCheck(13)}},
+	"416":  {"PROD/SERV LOC", []field{nCheck(13)}},
+	"417":  {"PARTY", []field{nCheck(13)}},
+	"420":  {"SHIP TO POST", []field{xVar(20)}},
+	"421":  {"SHIP TO POST", []field{n(3), xVar(9)}},
+	"422":  {"ORIGIN", []field{n(3)}},
+	"423":  {"COUNTRY - INITIAL PROCESS.", []field{n(3), nVar(12)}},
+	"424":  {"COUNTRY - PROCESS.", []field{n(3)}},
+	"425":  {"COUNTRY - DISASSEMBLY", []field{n(3), nVar(12)}},
+	"426":  {"COUNTRY - FULL PROCESS", []field{n(3)}},
+	"427":  {"ORIGIN SUBDIVISION", []field{xVar(3)}},
+	"4300": {"SHIP TO COMP", []field{xVar(35)}},
+	"4301": {"SHIP TO NAME", []field{xVar(35)}},
+	"4302": {"SHIP TO ADD1", []field{xVar(70)}},
+	"4303": {"SHIP TO ADD2", []field{xVar(70)}},
+	"4304": {"SHIP TO SUB", []field{xVar(70)}},
+	"4305": {"SHIP TO LOC", []field{xVar(70)}},
+	"4306": {"SHIP TO REG", []field{xVar(70)}},
+	"4307": {"SHIP TO COUNTRY", []field{x(2)}},
+	"4308": {"SHIP TO PHONE", []field{xVar(30)}},
+	"4309": {"SHIP TO GEO", []field{n(20)}},
+	"4310": {"RTN TO COMP", []field{xVar(35)}},
+	"4311": {"RTN TO NAME", []field{xVar(35)}},
+	"4312": {"RTN TO ADD1", []field{xVar(70)}},
+	"4313": {"RTN TO ADD2", []field{xVar(70)}},
+	"4314": {"RTN TO SUB", []field{xVar(70)}},
+	"4315": {"RTN TO LOC", []field{xVar(70)}},
+	"4316": {"RTN TO REG", []field{xVar(70)}},
+	"4317": {"RTN TO COUNTRY", []field{x(2)}},
+	"4318": {"RTN TO POST", []field{xVar(20)}},
+	"4319": {"RTN TO PHONE", []field{xVar(30)}},
+	"4320": {"SRV DESCRIPTION", []field{xVar(35)}},
+	"4321": {"DANGEROUS GOODS", []field{n(1)}},
+	"4322": {"AUTH LEAVE", []field{n(1)}},
+	"4323": {"SIG REQUIRED", []field{n(1)}},
+	"4324": {"NBEF DEL DT", []field{nDateTime(10, 10)}},
+	"4325": {"NAFT DEL DT", []field{nDateTime(10, 10)}},
+	"4326": {"REL DATE", []field{nDate()}},
+	"4330": {"MAX TEMP F", []field{n(6), negative()}},
+	"4331": {"MAX TEMP C", []field{n(6), negative()}},
+	"4332": {"MIN TEMP F", []field{n(6), negative()}},
+	"4333": {"MIN TEMP C", []field{n(6), negative()}},
+	"7001": {"NSN", []field{n(13)}},
+	"7002": {"MEAT CUT", []field{xVar(30)}},
+	"7003": {"EXPIRY TIME", []field{nDateTime(10, 10)}},
+	"7004": {"ACTIVE POTENCY", []field{nVar(4)}},
+	"7005": {"CATCH AREA", []field{xVar(12)}},
+	"7006": {"FIRST FREEZE DATE", []field{nDate()}},
+	"7007": {"HARVEST DATE", []field{nDate(), optional(nDate())}},
+	"7008": {"AQUATIC SPECIES", []field{xVar(3)}},
+	"7009": {"FISHING GEAR TYPE", []field{xVar(10)}},
+	"7010": {"PROD METHOD", []field{xVar(2)}},
+	"7011": {"TEST BY DATE", []field{nDateTime(6, 10)}},
+	"7020": {"REFURB LOT", []field{xVar(20)}},
+	"7021": {"FUNC STAT", []field{xVar(20)}},
+	"7022": {"REV STAT", []field{xVar(20)}},
+	"7023": {"GIAI - ASSEMBLY", []field{xVar(30)}},
+	"7040": {"UIC+EXT", []field{n(1), x(3)}},
+	"710":  {"NHRN PZN", []field{xVar(20)}},
+	"711":  {"NHRN CIP", []field{xVar(20)}},
+	"712":  {"NHRN CN", []field{xVar(20)}},
+	"713":  {"NHRN DRN", []field{xVar(20)}},
+	"714":  {"NHRN AIM", []field{xVar(20)}},
+	"715":  {"NHRN NDC", []field{xVar(20)}},
+	"7240": {"PROTOCOL", []field{xVar(20)}},
+	"7241": {"AIDC MEDIA TYPE", []field{n(2)}},
+	"7242": {"VCN", []field{xVar(25)}},
+	"7250": {"DOB", []field{n(8)}},
+	"7251": {"DOB TIME", []field{n(12)}},
+	"7252": {"BIO SEX", []field{n(1)}},
+	"7253": {"FAMILY NAME", []field{xVar(40)}},
+	"7254": {"GIVEN NAME", []field{xVar(40)}},
+	"7255": {"SUFFIX", []field{xVar(10)}},
+	"7256": {"FULL NAME", []field{xVar(90)}},
+	"7257": {"PERSON ADDR", []field{xVar(70)}},
+	"7258": {"BIRTH SEQUENCE", []field{n(1), x(1), n(1)}},
+	"7259": {"BABY", []field{xVar(40)}},
+	"8001": {"DIMENSIONS", []field{n(14)}},
+	"8002": {"CMT No.", []field{xVar(20)}},
+	"8003": {"GRAI", []field{nCheck(14), xVar(16)}},
+	"8004": {"GIAI", []field{xVar(30)}},
+	"8005": {"PRICE PER UNIT", []field{n(6)}},
+	"8006": {"ITIP", []field{nCheck(14), n(4)}},
+	"8007": {"IBAN", []field{xVar(34)}},
+	"8008": {"PROD TIME", []field{nDateTime(8, 12)}},
+	"8009": {"OPTSEN", []field{xVar(50)}},
+	"8010": {"CPID", []field{yVar(30)}},
+	"8011": {"CPID SERIAL", []field{nVar(12)}},
+	"8012": {"VERSION", []field{xVar(20)}},
+	"8013": {"GMN", []field{xVar(25)}},
+	"8017": {"GSRN - PROVIDER", []field{nCheck(18)}},
+	"8018": {"GSRN - RECIPIENT", []field{nCheck(18)}},
+	"8019": {"SRIN", []field{nVar(10)}},
+	"8020": {"REF No.", []field{xVar(25)}},
+	"8026": {"ITIP CONTENT", []field{nCheck(14), n(4)}},
+	"8030": {"DIGSIG", []field{zVar(90)}},
+	"8110": {"COUPON", []field{xVar(70)}},
+	"8111": {"POINTS", []field{n(4)}},
+	"8112": {"COUPON", []field{xVar(70)}},
+	"8200": {"PRODUCT URL", []field{xVar(70)}},
+	"90":   {"INTERNAL", []field{xVar(30)}},
+}
+
+func init() {
+	// the last digit of measures shows position of decimal point
+	measures := []struct {
+		from, to int
+		title    string
+	}{
+		{310, 316, "NET WEIGHT, DIMENSION"},
+		{320, 329, "NET WEIGHT, DIMENSION (imperial)"},
+		{330, 337, "GROSS WEIGHT, DIMENSION"},
+		{340, 349, "GROSS WEIGHT, DIMENSION (imperial)"},
+		{350, 357, "AREA, NET VOLUME"},
+		{360, 369, "NET VOLUME (imperial)"},
+	}
+	for _, m := range measures {
+		for ai := m.from; ai <= m.to; ai++ {
+			for d := 0; d <= 5; d++ {
+				applicationIdentifiers[fmt.Sprintf("%d%d", ai, d)] = applicationIdentifier{m.title, []field{n(6)}}
+			}
+		}
+	}
+
+	for d := 0; d <= 9; d++ {
+		applicationIdentifiers[fmt.Sprintf("390%d", d)] = applicationIdentifier{"AMOUNT", []field{nVar(15)}}
+		applicationIdentifiers[fmt.Sprintf("391%d", d)] = applicationIdentifier{"AMOUNT", []field{n(3), nVar(15)}}
+		applicationIdentifiers[fmt.Sprintf("392%d", d)] = applicationIdentifier{"PRICE", []field{nVar(15)}}
+		applicationIdentifiers[fmt.Sprintf("393%d", d)] = applicationIdentifier{"PRICE", []field{n(3), nVar(15)}}
+		applicationIdentifiers[fmt.Sprintf("394%d", d)] = applicationIdentifier{"PRCNT OFF", []field{n(4)}}
+		applicationIdentifiers[fmt.Sprintf("395%d", d)] = applicationIdentifier{"PRICE/UoM", []field{n(6)}}
+		applicationIdentifiers[fmt.Sprintf("703%d", d)] = applicationIdentifier{"PROCESSOR", []field{n(3), xVar(27)}}
+		applicationIdentifiers[fmt.Sprintf("723%d", d)] = applicationIdentifier{"CERT", []field{x(2), xVar(28)}}
+	}
+
+	// company internal information
+	for ai := 91; ai <= 99; ai++ {
+		applicationIdentifiers[fmt.Sprint(ai)] = applicationIdentifier{"INTERNAL", []field{xVar(90)}}
+	}
+}
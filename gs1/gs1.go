@@ -0,0 +1,230 @@
+// Package gs1 parses and validates GS1 element strings which are encoded in GS1 QR Code
+package gs1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GroupSeparator terminates element strings whose length is not predefined
+// it is encoded as FNC1 in QR code
+const GroupSeparator = "\x1d"
+
+// Element is a pair of application identifier and its data
+type Element struct {
+	AI   string
+	Data string
+}
+
+// Parse parses human readable element strings like "(01)09506000134352(17)201225",
+// and validates each element by the format of its application identifier
+// "(" in data is not supported because it is interpreted as the beginning of the next application identifier
+func Parse(s string) ([]Element, error) {
+	if s == "" {
+		return nil, fmt.Errorf("element string is empty")
+	}
+
+	var elements []Element
+	for s != "" {
+		if s[0] != '(' {
+			return nil, fmt.Errorf("application identifier must be enclosed in parentheses: %q", s)
+		}
+
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return nil, fmt.Errorf("application identifier is not closed: %q", s)
+		}
+		ai := s[1:end]
+		s = s[end+1:]
+
+		next := strings.IndexByte(s, '(')
+		if next < 0 {
+			next = len(s)
+		}
+		e := Element{AI: ai, Data: s[:next]}
+		s = s[next:]
+
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+		elements = append(elements, e)
+	}
+	return elements, nil
+}
+
+// Validate returns error if data does not match the format of the application identifier
+func (e Element) Validate() error {
+	ai, ok := applicationIdentifiers[e.AI]
+	if !ok {
+		return fmt.Errorf("unknown application identifier (%s)", e.AI)
+	}
+
+	data := e.Data
+	for i, f := range ai.fields {
+		// only the last field can have variable length
+		length := f.max
+		if i == len(ai.fields)-1 {
+			if len(data) < f.min || len(data) > f.max {
+				return fmt.Errorf("%s (%s) must have %s, but got %d characters", ai.title, e.AI, f.lengthString(), len(data))
+			}
+			length = len(data)
+		} else if len(data) < length {
+			return fmt.Errorf("%s (%s) is too short: %q", ai.title, e.AI, e.Data)
+		}
+
+		if err := f.validate(data[:length]); err != nil {
+			return fmt.Errorf("%s (%s): %w", ai.title, e.AI, err)
+		}
+		data = data[length:]
+	}
+	return nil
+}
+
+// lengthString returns description of length of the field
+func (f field) lengthString() string {
+	if f.min == f.max {
+		return fmt.Sprintf("%d characters", f.max)
+	}
+	return fmt.Sprintf("%d to %d characters", f.min, f.max)
+}
+
+// validate returns error if data has invalid characters, check digit or date
+func (f field) validate(data string) error {
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if f.set == digits && !isDigit(c) {
+			return fmt.Errorf("%q must be numeric", data)
+		}
+		if f.set != digits && !f.set.contains(c) {
+			return fmt.Errorf("%q cannot be used in %q", c, data)
+		}
+	}
+
+	if f.date && data != "" {
+		if err := validateDate(data, f.zeroDay); err != nil {
+			return err
+		}
+	}
+
+	if f.checkDigit {
+		want := CheckDigit(data[:len(data)-1])
+		if got := data[len(data)-1]; got != want {
+			return fmt.Errorf("check digit of %q must be %c, but got %c", data, want, got)
+		}
+	}
+	return nil
+}
+
+// CheckDigit returns check digit of numeric data like GTIN and SSCC
+// digits are weighted by 3 and 1 alternately from the right, and the check digit rounds the sum up to a multiple of 10
+// reference: GS1 General Specifications 7.9.1
+func CheckDigit(data string) byte {
+	sum := 0
+	for i := 0; i < len(data); i++ {
+		d := int(data[len(data)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte((10-sum%10)%10) + '0'
+}
+
+// Payload validates and concatenates elements for FNC1 in first position mode
+// GS follows data of application identifiers whose length is not predefined, except for the last element
+func Payload(elements []Element) (string, error) {
+	var b strings.Builder
+	for i, e := range elements {
+		if err := e.Validate(); err != nil {
+			return "", err
+		}
+
+		b.WriteString(e.AI)
+		b.WriteString(e.Data)
+
+		if i < len(elements)-1 && !predefinedLengths[e.AI[:2]] {
+			b.WriteString(GroupSeparator)
+		}
+	}
+	return b.String(), nil
+}
+
+// Encode parses human readable element strings, and returns payload for FNC1 in first position mode
+func Encode(s string) (string, error) {
+	elements, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return Payload(elements)
+}
+
+// validateDate returns error if data is not date YYMMDD followed by optional HH, MM and SS
+// century of the year is not determined, and the year is regarded as 20YY to check February 29
+// reference: GS1 General Specifications 7.12
+func validateDate(data string, zeroDay bool) error {
+	if len(data) < 6 || len(data) > 12 || len(data)%2 != 0 {
+		return fmt.Errorf("%q is not date YYMMDD with optional HHMMSS", data)
+	}
+	pair := func(i int) int {
+		return int(data[i]-'0')*10 + int(data[i+1]-'0')
+	}
+
+	year, month, day := 2000+pair(0), pair(2), pair(4)
+	if month < 1 || month > 12 {
+		return fmt.Errorf("month of %q must be 01 to 12", data)
+	}
+	if (day != 0 || !zeroDay) && (day < 1 || day > daysIn(year, month)) {
+		return fmt.Errorf("day of %q must be 01 to %02d", data, daysIn(year, month))
+	}
+
+	limits := []int{24, 60, 60}
+	for i := 6; i < len(data); i += 2 {
+		if limit := limits[(i-6)/2]; pair(i) >= limit {
+			return fmt.Errorf("time of %q must be less than %d at index %d", data, limit, i)
+		}
+	}
+	return nil
+}
+
+// daysIn returns number of days in the month
+func daysIn(year, month int) int {
+	switch month {
+	case 2:
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isEncodable returns true if c is in GS1 AI encodable character set 82
+// reference: GS1 General Specifications 7.11
+func isEncodable(c byte) bool {
+	if isDigit(c) || 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' {
+		return true
+	}
+	return strings.IndexByte(`!"%&'()*+,-./:;<=>?_`, c) >= 0
+}
+
+// contains returns true if c is in the character set
+// reference: GS1 General Specifications 7.11
+func (cs characterSet) contains(c byte) bool {
+	switch cs {
+	case digits:
+		return isDigit(c)
+	case cset39:
+		return isDigit(c) || 'A' <= c && c <= 'Z' || c == '#' || c == '-' || c == '/'
+	case cset64:
+		return isDigit(c) || 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || c == '-' || c == '_' || c == '='
+	case minusSign:
+		return c == '-'
+	}
+	return isEncodable(c)
+}
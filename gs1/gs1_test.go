@@ -0,0 +1,287 @@
+package gs1
+
+import "testing"
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want byte
+	}{
+		{name: "GTIN-14", data: "0950600013435", want: '2'},
+		{name: "GTIN-13", data: "400638133393", want: '1'},
+		{name: "SSCC", data: "10614141123456789", want: '7'},
+		{name: "sum is multiple of 10", data: "0000000000000", want: '0'},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := CheckDigit(test.data)
+			if result != test.want {
+				t.Errorf("expected %c, got %c\n", test.want, result)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []Element
+		wantErr bool
+	}{
+		{
+			name: "GTIN and expiry",
+			src:  "(01)09506000134352(17)201225",
+			want: []Element{{AI: "01", Data: "09506000134352"}, {AI: "17", Data: "201225"}},
+		},
+		{
+			name: "variable length and measure",
+			src:  "(10)ABC-123(3103)001250(21)12345",
+			want: []Element{{AI: "10", Data: "ABC-123"}, {AI: "3103", Data: "001250"}, {AI: "21", Data: "12345"}},
+		},
+		{
+			name: "GRAI has check digit and serial",
+			src:  "(8003)00614141000418A1",
+			want: []Element{{AI: "8003", Data: "00614141000418A1"}},
+		},
+		{
+			name: "identifiers of components and parts",
+			src:  "(8010)ABC#12-3/X(8011)123456789012(8012)V1.2",
+			want: []Element{{AI: "8010", Data: "ABC#12-3/X"}, {AI: "8011", Data: "123456789012"}, {AI: "8012", Data: "V1.2"}},
+		},
+		{
+			name: "digital signature and model number",
+			src:  "(8030)MEUCIQD_tn-A=(8013)1987654Ad4X4bL5ttr2310c2K",
+			want: []Element{{AI: "8030", Data: "MEUCIQD_tn-A="}, {AI: "8013", Data: "1987654Ad4X4bL5ttr2310c2K"}},
+		},
+		{
+			name: "harvest dates and protocol",
+			src:  "(7007)240301240315(7240)ABC(4300)ACME-LOGISTICS(714)ABC123",
+			want: []Element{{AI: "7007", Data: "240301240315"}, {AI: "7240", Data: "ABC"}, {AI: "4300", Data: "ACME-LOGISTICS"}, {AI: "714", Data: "ABC123"}},
+		},
+		{
+			name: "MTO GTIN",
+			src:  "(03)09506000134352",
+			want: []Element{{AI: "03", Data: "09506000134352"}},
+		},
+		{
+			name: "negative temperature",
+			src:  "(4331)001250-(4330)003200",
+			want: []Element{{AI: "4331", Data: "001250-"}, {AI: "4330", Data: "003200"}},
+		},
+		{
+			name: "day of date can be 00",
+			src:  "(17)201200",
+			want: []Element{{AI: "17", Data: "201200"}},
+		},
+		{
+			name: "February 29 of leap year",
+			src:  "(11)240229",
+			want: []Element{{AI: "11", Data: "240229"}},
+		},
+		{
+			name: "date and time",
+			src:  "(8008)2012251530(7003)2012252359",
+			want: []Element{{AI: "8008", Data: "2012251530"}, {AI: "7003", Data: "2012252359"}},
+		},
+		{
+			name:    "month 13 and day 99",
+			src:     "(11)991399",
+			wantErr: true,
+		},
+		{
+			name:    "February 29 of common year",
+			src:     "(11)230229",
+			wantErr: true,
+		},
+		{
+			name:    "day 00 is not allowed",
+			src:     "(7006)240300",
+			wantErr: true,
+		},
+		{
+			name:    "hour 24",
+			src:     "(7003)2012252400",
+			wantErr: true,
+		},
+		{
+			name:    "minute of time is not complete",
+			src:     "(8008)201225153",
+			wantErr: true,
+		},
+		{
+			name:    "temperature is followed by other than minus sign",
+			src:     "(4330)001234A",
+			wantErr: true,
+		},
+		{
+			name:    "unknown application identifier",
+			src:     "(23)ABC123",
+			wantErr: true,
+		},
+		{
+			name:    "character out of character set 39",
+			src:     "(8010)abc",
+			wantErr: true,
+		},
+		{
+			name:    "wrong check digit of GTIN",
+			src:     "(01)09506000134353",
+			wantErr: true,
+		},
+		{
+			name:    "wrong check digit of SSCC",
+			src:     "(00)106141411234567890",
+			wantErr: true,
+		},
+		{
+			name:    "fixed length is too short",
+			src:     "(17)2012",
+			wantErr: true,
+		},
+		{
+			name:    "variable length is too long",
+			src:     "(10)123456789012345678901",
+			wantErr: true,
+		},
+		{
+			name:    "numeric field has letters",
+			src:     "(30)12A",
+			wantErr: true,
+		},
+		{
+			name:    "character which cannot be encoded",
+			src:     "(21)AB#C",
+			wantErr: true,
+		},
+		{
+			name:    "application identifier is too long",
+			src:     "(99999)1",
+			wantErr: true,
+		},
+		{
+			name:    "application identifier is not numeric",
+			src:     "(AB)1",
+			wantErr: true,
+		},
+		{
+			name:    "without parentheses",
+			src:     "0109506000134352",
+			wantErr: true,
+		},
+		{
+			name:    "parenthesis is not closed",
+			src:     "(01",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Parse(test.src)
+			if (err != nil) != test.wantErr {
+				t.Errorf("expected error %v, got %v\n", test.wantErr, err)
+				return
+			}
+			if len(result) != len(test.want) {
+				t.Errorf("expected %v, got %v\n", test.want, result)
+				return
+			}
+			for i, want := range test.want {
+				if result[i] != want {
+					t.Errorf("expected %v, got %v at index %d\n", want, result[i], i)
+				}
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "predefined length does not need separator",
+			src:  "(01)09506000134352(17)201225",
+			want: "0109506000134352" + "17201225",
+		},
+		{
+			name: "variable length is terminated by separator",
+			src:  "(10)ABC123(17)201225(21)XYZ",
+			want: "10ABC123\x1d" + "17201225" + "21XYZ",
+		},
+		{
+			name: "fixed length which is not predefined needs separator",
+			src:  "(7003)2012251530(01)09506000134352",
+			want: "70032012251530\x1d" + "0109506000134352",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Encode(test.src)
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if result != test.want {
+				t.Errorf("expected %q, got %q\n", test.want, result)
+			}
+		})
+	}
+}
+
+func TestEncodeUnknownApplicationIdentifier(t *testing.T) {
+	// unknown application identifiers which have prefixes of predefined length would be read as corrupted GTIN
+	tests := []string{"(011)ABC(10)X", "(019)12(21)X", "(23)ABC(01)09506000134352"}
+
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			if result, err := Encode(src); err == nil {
+				t.Errorf("expected error, got %q\n", result)
+			}
+		})
+	}
+}
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		elements []Element
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "variable length is terminated by separator",
+			elements: []Element{{AI: "10", Data: "ABC123"}, {AI: "01", Data: "09506000134352"}},
+			want:     "10ABC123\x1d" + "0109506000134352",
+		},
+		{
+			name:     "application identifier is too short",
+			elements: []Element{{AI: "1", Data: "x"}, {AI: "10", Data: "y"}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid data",
+			elements: []Element{{AI: "01", Data: "09506000134353"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Payload(test.elements)
+			if (err != nil) != test.wantErr {
+				t.Errorf("expected error %v, got %v\n", test.wantErr, err)
+				return
+			}
+			if result != test.want {
+				t.Errorf("expected %q, got %q\n", test.want, result)
+			}
+		})
+	}
+}
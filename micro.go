@@ -0,0 +1,283 @@
+package qrcode
+
+import (
+	"fmt"
+
+	"github.com/ksrnnb/qrcode/bitset"
+	"github.com/ksrnnb/qrcode/reedsolomon"
+)
+
+const (
+	microQuietZoneSize = 2
+
+	maxMicroVersion = 4
+)
+
+// microSymbol is a combination of version and error correction level of Micro QR code
+type microSymbol struct {
+	version int
+	ecl     ErrorCorrectionLevel
+
+	// number is symbol number in format information
+	number int
+
+	// dataBits is number of data bits, and the last data codeword of M1 and M3 is 4 bits
+	dataBits    int
+	ecCodeWords int
+}
+
+// microSymbols shows Micro QR code symbols in order of capacity
+// M1 has only error detection, and it is treated as level L
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 7, Table 9 and Table 13
+var microSymbols = []microSymbol{
+	{version: 1, ecl: ECL_Low, number: 0, dataBits: 20, ecCodeWords: 2},
+	{version: 2, ecl: ECL_Low, number: 1, dataBits: 40, ecCodeWords: 5},
+	{version: 2, ecl: ECL_Medium, number: 2, dataBits: 32, ecCodeWords: 6},
+	{version: 3, ecl: ECL_Low, number: 3, dataBits: 84, ecCodeWords: 6},
+	{version: 3, ecl: ECL_Medium, number: 4, dataBits: 68, ecCodeWords: 8},
+	{version: 4, ecl: ECL_Low, number: 5, dataBits: 128, ecCodeWords: 8},
+	{version: 4, ecl: ECL_Medium, number: 6, dataBits: 112, ecCodeWords: 10},
+	{version: 4, ecl: ECL_High, number: 7, dataBits: 80, ecCodeWords: 14},
+}
+
+// microMaskPatterns maps mask pattern of Micro QR code to mask pattern of QR code
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 10
+var microMaskPatterns = []uint8{1, 4, 6, 7}
+
+// microModeIndicators shows mode indicators of Micro QR code, and their length is version - 1 bits
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 2
+var microModeIndicators = map[ModeIndicator]int{
+	Numeric:      0b00,
+	AlphaNumeric: 0b01,
+	EightBits:    0b10,
+	Kanji:        0b11,
+}
+
+// microCharacterCountIndicatorBits returns character count indicator bits of Micro QR code
+// it returns 0 if the mode cannot be used in the version
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 3
+func microCharacterCountIndicatorBits(version int, mode ModeIndicator) int {
+	bits := map[ModeIndicator][]int{
+		Numeric:      {3, 4, 5, 6},
+		AlphaNumeric: {0, 3, 4, 5},
+		EightBits:    {0, 0, 4, 5},
+		Kanji:        {0, 0, 3, 4},
+	}[mode]
+	if bits == nil || version < 1 || version > maxMicroVersion {
+		return 0
+	}
+	return bits[version-1]
+}
+
+// microHeaderBits returns headerBitsFunc of Micro QR code of the version
+func microHeaderBits(version int) headerBitsFunc {
+	return func(mode ModeIndicator) (int, bool) {
+		ccBits := microCharacterCountIndicatorBits(version, mode)
+		if ccBits == 0 {
+			return 0, false
+		}
+		return version - 1 + ccBits, true
+	}
+}
+
+// terminatorBits returns length of terminator of Micro QR code
+func (ms microSymbol) terminatorBits() int {
+	return ms.version*2 + 1
+}
+
+// dataCodeWords returns number of data codewords including the last 4 bits codeword
+func (ms microSymbol) dataCodeWords() int {
+	return (ms.dataBits + 7) / 8
+}
+
+// fits returns true if segments can be encoded in the symbol
+func (ms microSymbol) fits(segments []Segment) bool {
+	bits := 0
+	header := microHeaderBits(ms.version)
+	for _, s := range segments {
+		headerBits, ok := header(s.Mode)
+		if !ok {
+			return false
+		}
+		// number of characters must be expressed by character count indicator
+		if characterCount(s.Mode, s.Data) >= 1<<microCharacterCountIndicatorBits(ms.version, s.Mode) {
+			return false
+		}
+		bits += headerBits + s.dataBitLength()
+	}
+	return bits <= ms.dataBits
+}
+
+// NewMicro creates Micro QR code of the smallest symbol which can contain content
+// ecl must be ECL_Low, ECL_Medium or ECL_High, and M1 is used only for ECL_Low
+func NewMicro(ecl ErrorCorrectionLevel, content string) (*QRCode, error) {
+	if ecl == ECL_Highest {
+		return nil, fmt.Errorf("error correction level H cannot be used in Micro QR code")
+	}
+
+	for _, symbol := range microSymbols {
+		if symbol.ecl != ecl {
+			continue
+		}
+
+		segments := optimizeSymbolSegments(content, microHeaderBits(symbol.version), encodeUTF8, false)
+		if segments == nil || !symbol.fits(segments) {
+			continue
+		}
+		return newMicroQRCodeWithBestMask(symbol, segments), nil
+	}
+	return nil, fmt.Errorf("content is too long to be encoded in Micro QR code")
+}
+
+// encodeMicroData encodes segments, and returns data bits followed by error correction codewords
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.4.10
+func encodeMicroData(symbol microSymbol, segments []Segment) *bitset.BitSet {
+	bs := bitset.NewBitSet(symbol.dataBits)
+
+	for _, s := range segments {
+		bs.SetInt(microModeIndicators[s.Mode], symbol.version-1)
+		addCharacterCountIndicator(bs, microCharacterCountIndicatorBits(symbol.version, s.Mode), characterCount(s.Mode, s.Data))
+		addSrcData(bs, s.Mode, s.Data)
+	}
+
+	// terminator is omitted or truncated if data bits are not enough
+	for i := 0; i < symbol.terminatorBits() && bs.Position() < bs.Length(); i++ {
+		bs.SetBool(false)
+	}
+
+	// pads to the codeword boundary
+	for bs.Position()%8 != 0 && bs.Position() < bs.Length() {
+		bs.SetBool(false)
+	}
+
+	paddingPatterns := []int{0b11101100, 0b00010001}
+	for i := 0; bs.Length()-bs.Position() >= 8; i++ {
+		bs.SetInt(paddingPatterns[i%2], 8)
+	}
+
+	// the last 4 bits codeword of M1 and M3 is 0000
+	for bs.Position() < bs.Length() {
+		bs.SetBool(false)
+	}
+
+	// the last 4 bits codeword is placed in the upper bits of a byte to calculate error correction codewords
+	data := bitset.NewBitSet(symbol.dataCodeWords() * 8)
+	for i := 0; i < symbol.dataCodeWords(); i++ {
+		v := bs.ByteAt(i)
+		if rest := symbol.dataBits - i*8; rest < 8 {
+			v <<= 8 - rest
+		}
+		data.SetByte(v)
+	}
+	encoded := reedsolomon.Encode(data, symbol.ecCodeWords)
+
+	result := bs.Clone()
+	for i := 0; i < symbol.ecCodeWords; i++ {
+		result.SetByte(encoded.ByteAt(symbol.dataCodeWords() + i))
+	}
+	return result
+}
+
+// newMicroQRCodeWithBestMask encodes segments and returns Micro QR code which has the highest score of mask evaluation
+func newMicroQRCodeWithBestMask(symbol microSymbol, segments []Segment) *QRCode {
+	data := encodeMicroData(symbol, segments)
+
+	var q *QRCode
+	score := -1
+	for mask := uint8(0); mask < uint8(len(microMaskPatterns)); mask++ {
+		newQR := newMicroQRCode(symbol, mask, data)
+		if s := newQR.microScore(); s > score {
+			score = s
+			q = newQR
+		}
+	}
+	return q
+}
+
+func newMicroQRCode(symbol microSymbol, mask uint8, data *bitset.BitSet) *QRCode {
+	q := &QRCode{
+		version:   symbol.version,
+		ecl:       symbol.ecl,
+		mask:      mask,
+		data:      data,
+		width:     microSymbolSize(symbol.version),
+		height:    microSymbolSize(symbol.version),
+		kind:      kindMicro,
+		quietZone: microQuietZoneSize,
+	}
+	q.initModules()
+	q.buildMicro(symbol.number)
+
+	return q
+}
+
+// microSymbolSize returns number of modules of a side of Micro QR code
+func microSymbolSize(version int) int {
+	return 9 + 2*version
+}
+
+// maskPattern returns mask pattern of QR code which is applied to data modules
+func (q *QRCode) maskPattern() uint8 {
+	switch q.kind {
+	case kindMicro:
+		return microMaskPatterns[q.mask]
+	case kindRMQR:
+		return rmqrMaskPattern
+	}
+	return q.mask
+}
+
+func (q *QRCode) buildMicro(symbolNumber int) {
+	// finder pattern is only top left
+	q.add2dPattern(0, 0, finderPattern)
+	q.add2dPattern(finderPatternSize, 0, separatorVerticalPattern)
+	q.add2dPattern(0, finderPatternSize, separatorHorizontalPattern)
+
+	// timing patterns are on the top and left edges
+	for i := finderPatternSize + 1; i < q.width; i++ {
+		v := i%2 == 0
+		q.add(i, 0, v)
+		q.add(0, i, v)
+	}
+
+	q.addMicroFormatInfo(symbolNumber)
+	q.addData()
+}
+
+// addMicroFormatInfo adds format information around the finder pattern
+// bits 0-7 are on column 8 from row 1 to 8, and bits 8-14 are on row 8 from column 7 to 1
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.9.2
+func (q *QRCode) addMicroFormatInfo(symbolNumber int) {
+	fi := MicroFormatInfo(symbolNumber, q.mask)
+	last := formatInfoLength - 1
+
+	for i := 0; i <= 7; i++ {
+		q.add(finderPatternSize+1, i+1, fi.GetValue(last-i))
+	}
+	for i := 8; i <= 14; i++ {
+		q.add(15-i, finderPatternSize+1, fi.GetValue(last-i))
+	}
+}
+
+// microScore returns evaluation score of mask of Micro QR code
+// SUM1 and SUM2 are numbers of dark modules on the right and bottom edges, and the higher score is better
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.8.3.2
+func (q *QRCode) microScore() int {
+	sum1 := 0
+	sum2 := 0
+	for y := 1; y < q.height; y++ {
+		if q.get(q.width-1, y) {
+			sum1++
+		}
+	}
+	for x := 1; x < q.width; x++ {
+		if q.get(x, q.height-1) {
+			sum2++
+		}
+	}
+
+	if sum1 <= sum2 {
+		return sum1*16 + sum2
+	}
+	return sum2*16 + sum1
+}
@@ -0,0 +1,123 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeMicroData(t *testing.T) {
+	tests := []struct {
+		name   string
+		symbol microSymbol
+		src    string
+		want   []byte
+	}{
+		{
+			// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Annex I.3
+			name:   "M2-L numeric",
+			symbol: microSymbols[1],
+			src:    "01234567",
+			want: []byte{
+				0b01000000, 0b00011000, 0b10101100, 0b11000011, 0b00000000,
+				0b10000110, 0b00001101, 0b00100010, 0b10101110, 0b00110000,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			segments := optimizeSymbolSegments(test.src, microHeaderBits(test.symbol.version), encodeUTF8, false)
+			result := encodeMicroData(test.symbol, segments)
+			for i, w := range test.want {
+				if result.ByteAt(i) != w {
+					t.Errorf("want %08b, but got %08b at index: %d\n", w, result.ByteAt(i), i)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeMicroDataHalfCodeWord(t *testing.T) {
+	// M1: 001 (count) 0001 (1) 000 (terminator) 000000 (padding) 0000 (last 4 bits codeword)
+	result := encodeMicroData(microSymbols[0], []Segment{{Mode: Numeric, Data: "1"}})
+	if result.Length() != 20+2*8 {
+		t.Errorf("expected %d, got %d\n", 20+2*8, result.Length())
+		return
+	}
+
+	want := "00100010000000000000"
+	for i, c := range want {
+		if result.GetValue(i) != (c == '1') {
+			t.Errorf("expected %c at pos %d\n", c, i)
+		}
+	}
+}
+
+func TestNewMicro(t *testing.T) {
+	tests := []struct {
+		name        string
+		ecl         ErrorCorrectionLevel
+		src         string
+		wantVersion int
+		wantSize    int
+	}{
+		{name: "M1 numeric", ecl: ECL_Low, src: "12345", wantVersion: 1, wantSize: 11},
+		{name: "M2 alpha numeric", ecl: ECL_Low, src: "AB-12", wantVersion: 2, wantSize: 13},
+		{name: "M2-M numeric", ecl: ECL_Medium, src: "12345678", wantVersion: 2, wantSize: 13},
+		{name: "M3 8 bits byte", ecl: ECL_Low, src: "abc", wantVersion: 3, wantSize: 15},
+		{name: "M3 kanji", ecl: ECL_Medium, src: "点茗", wantVersion: 3, wantSize: 15},
+		{name: "M4-Q", ecl: ECL_High, src: "hello", wantVersion: 4, wantSize: 17},
+		{name: "M4-L max numeric", ecl: ECL_Low, src: strings.Repeat("1", 35), wantVersion: 4, wantSize: 17},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := NewMicro(test.ecl, test.src)
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if q.version != test.wantVersion {
+				t.Errorf("expected %d, got %d\n", test.wantVersion, q.version)
+			}
+			if q.width != test.wantSize || q.height != test.wantSize {
+				t.Errorf("expected %d, got %dx%d\n", test.wantSize, q.width, q.height)
+			}
+			if len(q.modules) != test.wantSize+2*microQuietZoneSize {
+				t.Errorf("expected %d, got %d\n", test.wantSize+2*microQuietZoneSize, len(q.modules))
+			}
+		})
+	}
+}
+
+func TestNewMicroError(t *testing.T) {
+	tests := []struct {
+		name string
+		ecl  ErrorCorrectionLevel
+		src  string
+	}{
+		{name: "level H", ecl: ECL_Highest, src: "1"},
+		{name: "too long", ecl: ECL_Low, src: strings.Repeat("1", 36)},
+		{name: "8 bits byte in M4-Q", ecl: ECL_High, src: strings.Repeat("a", 10)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewMicro(test.ecl, test.src)
+			if err == nil {
+				t.Errorf("expected error, got nil\n")
+			}
+		})
+	}
+}
+
+func TestMicroFormatInfo(t *testing.T) {
+	// M3-L (011) and mask 01
+	fi := MicroFormatInfo(3, 1)
+	want := 0x734F
+	for i := 0; i < formatInfoLength; i++ {
+		if fi.GetValue(i) != ((want>>(formatInfoLength-1-i))&1 == 1) {
+			t.Errorf("unexpected bit at pos %d\n", i)
+		}
+	}
+}
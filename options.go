@@ -0,0 +1,97 @@
+package qrcode
+
+import "fmt"
+
+// Options is options of encoding
+type Options struct {
+	// ECL is error correction level, and the default is ECL_Medium
+	ECL ErrorCorrectionLevel
+
+	// UTF8ECI adds ECI 26 (UTF-8) before 8 bits byte segments
+	// scanners may interpret 8 bits byte data as ISO-8859-1 or guess the character set without it
+	UTF8ECI bool
+
+	// Transcode converts 8 bits byte segments to ISO-8859-1 to 16 or Shift_JIS if it makes the bitstream shorter than UTF-8,
+	// and adds ECI of the character set before them
+	// ECI 26 (UTF-8) is added if any character set cannot make it shorter
+	Transcode bool
+
+	// GS1 encodes content as GS1 element string with FNC1 in first position
+	// variable length fields of content are terminated by GS (0x1D)
+	GS1 bool
+
+	// ApplicationIndicator encodes content in the format of the industry specified by AIM with FNC1 in second position
+	// it is two digits ("00"-"99") or a single letter (a-z, A-Z)
+	ApplicationIndicator string
+
+	// Hanzi allows the segmenter to encode GB 2312 characters in hanzi mode
+	// hanzi mode is defined in GB/T 18284, and scanners may not support it
+	Hanzi bool
+
+	// MinVersion and MaxVersion limit versions which can be selected, and 0 means no limit
+	// the same value fixes the version, for example to keep the physical size of symbols
+	MinVersion int
+	MaxVersion int
+
+	// Mask fixes mask pattern (0-7) instead of selecting it by penalty
+	// it is nil by default
+	Mask *uint8
+
+	// BoostECL raises error correction level from ECL as long as the content fits in the same version
+	// the selected level can be got by QRCode.ECL
+	BoostECL bool
+}
+
+// versionRange returns the smallest and the largest versions which can be selected
+func (opts Options) versionRange() (int, int) {
+	from, to := minVersion, maxVersion
+	if opts.MinVersion != 0 {
+		from = opts.MinVersion
+	}
+	if opts.MaxVersion != 0 {
+		to = opts.MaxVersion
+	}
+	return from, to
+}
+
+// validate returns error if options cannot be used together
+func (opts Options) validate() error {
+	if opts.GS1 && opts.ApplicationIndicator != "" {
+		return fmt.Errorf("FNC1 in first position and second position cannot be used together")
+	}
+	if opts.ApplicationIndicator != "" {
+		if _, err := applicationIndicator(opts.ApplicationIndicator); err != nil {
+			return err
+		}
+	}
+
+	from, to := opts.versionRange()
+	if from < minVersion || to > maxVersion || from > to {
+		return fmt.Errorf("version range must be within %d to %d, but got %d to %d", minVersion, maxVersion, from, to)
+	}
+	if opts.Mask != nil && *opts.Mask > 0b111 {
+		return fmt.Errorf("mask must be 0 to 7, but got %d", *opts.Mask)
+	}
+	return nil
+}
+
+// NewWithOptions creates QR code of content with options
+func NewWithOptions(content string, opts Options) (*QRCode, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	info, err := findQRInfo(content, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Mask != nil {
+		data, err := encodeRawData(info)
+		if err != nil {
+			return nil, err
+		}
+		return newQRCode(info.version, info.ecl, *opts.Mask, data), nil
+	}
+	return newQRCodeWithBestMask(info)
+}
@@ -0,0 +1,154 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewWithOptionsVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		opts        Options
+		wantVersion int
+		wantErr     bool
+	}{
+		{
+			name:        "min version",
+			src:         "a",
+			opts:        Options{MinVersion: 5},
+			wantVersion: 5,
+		},
+		{
+			name:        "fixed version",
+			src:         "a",
+			opts:        Options{MinVersion: 3, MaxVersion: 3},
+			wantVersion: 3,
+		},
+		{
+			name:        "larger version than min version",
+			src:         strings.Repeat("a", 30),
+			opts:        Options{ECL: ECL_Highest, MinVersion: 2},
+			wantVersion: 4,
+		},
+		{
+			name:    "content does not fit in max version",
+			src:     strings.Repeat("a", 20),
+			opts:    Options{ECL: ECL_Medium, MaxVersion: 1},
+			wantErr: true,
+		},
+		{
+			name:    "min version is larger than max version",
+			src:     "a",
+			opts:    Options{MinVersion: 5, MaxVersion: 4},
+			wantErr: true,
+		},
+		{
+			name:    "max version is out of range",
+			src:     "a",
+			opts:    Options{MaxVersion: 41},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := NewWithOptions(test.src, test.opts)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("error is expected, but got nil\n")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if q.version != test.wantVersion {
+				t.Errorf("expected version %d, got %d\n", test.wantVersion, q.version)
+			}
+		})
+	}
+}
+
+func TestNewWithOptionsMask(t *testing.T) {
+	for mask := uint8(0); mask <= 0b111; mask++ {
+		m := mask
+		q, err := NewWithOptions("HELLO WORLD", Options{Mask: &m})
+		if err != nil {
+			t.Errorf("error: %v\n", err)
+			continue
+		}
+		if q.mask != mask {
+			t.Errorf("expected %d, got %d\n", mask, q.mask)
+		}
+	}
+
+	invalid := uint8(8)
+	if _, err := NewWithOptions("HELLO WORLD", Options{Mask: &invalid}); err == nil {
+		t.Errorf("error is expected, but got nil\n")
+	}
+}
+
+func TestNewWithOptionsBoostECL(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		opts        Options
+		wantVersion int
+		wantECL     ErrorCorrectionLevel
+	}{
+		{
+			// 74 bits: M has 128 bits, Q has 104 bits and H has 72 bits in version 1
+			name:        "boosted to Q",
+			src:         "HELLO WORLD",
+			opts:        Options{ECL: ECL_Low, BoostECL: true},
+			wantVersion: 1,
+			wantECL:     ECL_High,
+		},
+		{
+			name:        "boosted to H",
+			src:         "a",
+			opts:        Options{ECL: ECL_Low, BoostECL: true},
+			wantVersion: 1,
+			wantECL:     ECL_Highest,
+		},
+		{
+			name:        "not boosted",
+			src:         "HELLO WORLD",
+			opts:        Options{ECL: ECL_High, BoostECL: true},
+			wantVersion: 1,
+			wantECL:     ECL_High,
+		},
+		{
+			name:        "without option",
+			src:         "a",
+			opts:        Options{ECL: ECL_Low},
+			wantVersion: 1,
+			wantECL:     ECL_Low,
+		},
+		{
+			name:        "boosted in fixed version",
+			src:         strings.Repeat("a", 30),
+			opts:        Options{ECL: ECL_Low, MinVersion: 5, MaxVersion: 5, BoostECL: true},
+			wantVersion: 5,
+			wantECL:     ECL_Highest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := NewWithOptions(test.src, test.opts)
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if q.Version() != test.wantVersion {
+				t.Errorf("expected version %d, got %d\n", test.wantVersion, q.Version())
+			}
+			if q.ECL() != test.wantECL {
+				t.Errorf("expected ECL %02b, got %02b\n", test.wantECL, q.ECL())
+			}
+		})
+	}
+}
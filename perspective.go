@@ -0,0 +1,65 @@
+package qrcode
+
+import "math"
+
+// perspectiveTransform maps module coordinates (u, v) to image coordinates (x, y)
+// x = (a*u + b*v + c) / (g*u + h*v + 1), y = (d*u + e*v + f) / (g*u + h*v + 1)
+type perspectiveTransform struct {
+	a, b, c, d, e, f, g, h float64
+}
+
+// newPerspectiveTransform returns transform which maps 4 points of src to 4 points of dst
+// it returns false if 3 of the points are on a line
+func newPerspectiveTransform(src, dst [4]point) (perspectiveTransform, bool) {
+	// each pair of points gives 2 linear equations of 8 coefficients
+	var m [8][9]float64
+	for i := 0; i < 4; i++ {
+		u, v := src[i].x, src[i].y
+		x, y := dst[i].x, dst[i].y
+		m[2*i] = [9]float64{u, v, 1, 0, 0, 0, -u * x, -v * x, x}
+		m[2*i+1] = [9]float64{0, 0, 0, u, v, 1, -u * y, -v * y, y}
+	}
+
+	// Gaussian elimination with partial pivoting
+	for col := 0; col < 8; col++ {
+		pivot := col
+		for row := col + 1; row < 8; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-9 {
+			return perspectiveTransform{}, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+
+		for row := 0; row < 8; row++ {
+			if row == col {
+				continue
+			}
+			r := m[row][col] / m[col][col]
+			for k := col; k < 9; k++ {
+				m[row][k] -= r * m[col][k]
+			}
+		}
+	}
+
+	var coefficients [8]float64
+	for i := range coefficients {
+		coefficients[i] = m[i][8] / m[i][i]
+	}
+	return perspectiveTransform{
+		a: coefficients[0], b: coefficients[1], c: coefficients[2],
+		d: coefficients[3], e: coefficients[4], f: coefficients[5],
+		g: coefficients[6], h: coefficients[7],
+	}, true
+}
+
+// transform returns image coordinates of module coordinates p
+func (t perspectiveTransform) transform(p point) point {
+	w := t.g*p.x + t.h*p.y + 1
+	return point{
+		x: (t.a*p.x + t.b*p.y + t.c) / w,
+		y: (t.d*p.x + t.e*p.y + t.f) / w,
+	}
+}
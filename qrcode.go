@@ -7,23 +7,50 @@ import (
 	"image/color"
 	"image/png"
 	"math"
-	"unicode/utf8"
 
 	"github.com/ksrnnb/qrcode/bitset"
 )
 
 type QRCode struct {
+	version int
 	ecl     ErrorCorrectionLevel
 	mask    uint8
 	data    *bitset.BitSet
 	modules [][]bool
 	dirties [][]bool
-	size    int
+	width   int
+	height  int
+
+	kind      symbolKind
+	quietZone int
+}
+
+// Version returns version of the symbol
+// it is 1-4 for Micro QR code (M1-M4), and 1-32 for rMQR (R7x43-R17x139)
+func (q *QRCode) Version() int {
+	return q.version
 }
 
+// ECL returns error correction level of the symbol
+func (q *QRCode) ECL() ErrorCorrectionLevel {
+	return q.ecl
+}
+
+// symbolKind shows kind of symbol
+type symbolKind int
+
 const (
-	quietZoneSize     = 4
-	finderPatternSize = 7
+	kindQR symbolKind = iota
+	// version 1-4 means M1-M4
+	kindMicro
+	// version 1-32 means R7x43-R17x139
+	kindRMQR
+)
+
+const (
+	quietZoneSize        = 4
+	finderPatternSize    = 7
+	alignmentPatternSize = 5
 
 	up   = 1
 	down = 2
@@ -40,6 +67,14 @@ var (
 		{true, true, true, true, true, true, true},
 	}
 
+	alignmentPattern = [][]bool{
+		{true, true, true, true, true},
+		{true, false, false, false, true},
+		{true, false, true, false, true},
+		{true, false, false, false, true},
+		{true, true, true, true, true},
+	}
+
 	separatorHorizontalPattern = [][]bool{
 		{false, false, false, false, false, false, false, false},
 	}
@@ -58,57 +93,93 @@ var (
 type qrInfo struct {
 	version            int
 	ecl                ErrorCorrectionLevel
-	mode               ModeIndicator
 	dataCap            int // code cap = countDataCodeWords + countErrorCodeWords
 	countDataCodeWords int
-	srcCap             int
-	src                string
-}
-
-func newQRInfo(ecl ErrorCorrectionLevel, src string) qrInfo {
-	// supports only version 1
-	switch ecl {
-	case ECL_Low:
-		return qrInfo{
-			version:            1,
-			ecl:                ecl,
-			mode:               EightBits,
-			dataCap:            26,
-			countDataCodeWords: 19,
-			srcCap:             17,
-			src:                src,
+	segments           []Segment
+}
+
+func newQRInfo(version int, ecl ErrorCorrectionLevel, segments []Segment) qrInfo {
+	return qrInfo{
+		version:            version,
+		ecl:                ecl,
+		dataCap:            totalCodeWords(version, ecl),
+		countDataCodeWords: dataCodeWords(version, ecl),
+		segments:           segments,
+	}
+}
+
+// findQRInfo returns qrInfo of the smallest version which can contain src within the version range of opts
+func findQRInfo(src string, opts Options) (qrInfo, error) {
+	ecl := opts.ECL
+	from, to := opts.versionRange()
+
+	var segments []Segment
+	for version := from; version <= to; version++ {
+		// optimal segments change when character count indicator bits change
+		if version == from || version == 10 || version == 27 {
+			segments = buildSegments(src, version, opts)
 		}
-	case ECL_Medium:
-		return qrInfo{
-			version:            1,
-			ecl:                ecl,
-			mode:               EightBits,
-			dataCap:            26,
-			countDataCodeWords: 16,
-			srcCap:             14,
-			src:                src,
+
+		info := newQRInfo(version, ecl, segments)
+		if info.fits() {
+			if opts.BoostECL {
+				return info.boostECL(), nil
+			}
+			return info, nil
 		}
-	case ECL_High:
-		return qrInfo{
-			version:            1,
-			ecl:                ecl,
-			mode:               EightBits,
-			dataCap:            26,
-			countDataCodeWords: 13,
-			srcCap:             11,
-			src:                src,
+	}
+	return qrInfo{}, fmt.Errorf("content is too long, it needs %d bits but only %d bits can be encoded in version %d", segmentsBitLength(segments, to), dataCodeWords(to, ecl)*8, to)
+}
+
+// findQRInfoBySegments returns qrInfo of the smallest version which can contain segments
+func findQRInfoBySegments(ecl ErrorCorrectionLevel, segments []Segment) (qrInfo, error) {
+	for version := minVersion; version <= maxVersion; version++ {
+		info := newQRInfo(version, ecl, segments)
+		if info.fits() {
+			return info, nil
 		}
-	default: // Error Correction Level: H
-		return qrInfo{
-			version:            1,
-			ecl:                ecl,
-			mode:               EightBits,
-			dataCap:            26,
-			countDataCodeWords: 9,
-			srcCap:             7,
-			src:                src,
+	}
+	return qrInfo{}, fmt.Errorf("segments are too long, they need %d bits but only %d bits can be encoded", segmentsBitLength(segments, maxVersion), dataCodeWords(maxVersion, ecl)*8)
+}
+
+// fits returns true if segments can be encoded in the version
+func (qi qrInfo) fits() bool {
+	for _, s := range qi.segments {
+		bits := characterCountIndicatorBits(qi.version, s.Mode)
+		if bits == 0 {
+			continue
+		}
+		// number of characters must be expressed by character count indicator
+		if characterCount(s.Mode, s.Data) >= 1<<bits {
+			return false
 		}
 	}
+	return segmentsBitLength(qi.segments, qi.version) <= qi.countDataCodeWords*8
+}
+
+// eclOrder shows error correction levels in ascending order of recovery capacity
+var eclOrder = []ErrorCorrectionLevel{ECL_Low, ECL_Medium, ECL_High, ECL_Highest}
+
+// boostECL returns qrInfo of the highest error correction level which can contain segments in the same version
+func (qi qrInfo) boostECL() qrInfo {
+	boosted := qi
+	higher := false
+	for _, ecl := range eclOrder {
+		if ecl == qi.ecl {
+			higher = true
+			continue
+		}
+		if !higher {
+			continue
+		}
+
+		info := newQRInfo(qi.version, ecl, qi.segments)
+		if !info.fits() {
+			break
+		}
+		boosted = info
+	}
+	return boosted
 }
 
 func (qi qrInfo) countErrorCordWords() int {
@@ -116,11 +187,27 @@ func (qi qrInfo) countErrorCordWords() int {
 }
 
 func New(ecl ErrorCorrectionLevel, content string) (*QRCode, error) {
-	info := newQRInfo(ecl, content)
-	if utf8.RuneCountInString(content) > info.srcCap {
-		return nil, fmt.Errorf("this app supports only version 1 and 8 bits byte mode, must be less than %d characters", info.srcCap+1)
+	return NewWithOptions(content, Options{ECL: ecl})
+}
+
+// NewFromSegments creates QR code from segments whose modes are specified by caller
+// it returns error if a segment has characters which cannot be encoded in its mode
+func NewFromSegments(ecl ErrorCorrectionLevel, segments ...Segment) (*QRCode, error) {
+	for i, s := range segments {
+		if err := s.validate(); err != nil {
+			return nil, fmt.Errorf("segment %d: %w", i, err)
+		}
+	}
+
+	info, err := findQRInfoBySegments(ecl, segments)
+	if err != nil {
+		return nil, err
 	}
+	return newQRCodeWithBestMask(info)
+}
 
+// newQRCodeWithBestMask encodes data and returns QR code which has the lowest penalty
+func newQRCodeWithBestMask(info qrInfo) (*QRCode, error) {
 	data, err := encodeRawData(info)
 	if err != nil {
 		return nil, err
@@ -129,7 +216,7 @@ func New(ecl ErrorCorrectionLevel, content string) (*QRCode, error) {
 	var q *QRCode
 	penalty := math.MaxInt
 	for mask := uint8(0b000); mask <= uint8(0b111); mask++ {
-		newQR := newQRCode(ecl, mask, data)
+		newQR := newQRCode(info.version, info.ecl, mask, data)
 		if newQR.penalty() < penalty {
 			penalty = newQR.penalty()
 			q = newQR
@@ -138,38 +225,47 @@ func New(ecl ErrorCorrectionLevel, content string) (*QRCode, error) {
 	return q, nil
 }
 
-func newQRCode(ecl ErrorCorrectionLevel, mask uint8, data *bitset.BitSet) *QRCode {
-	// version1: module size per line is 21
-	size := 21
+func newQRCode(version int, ecl ErrorCorrectionLevel, mask uint8, data *bitset.BitSet) *QRCode {
+	size := symbolSize(version)
 
 	q := &QRCode{
-		ecl:     ecl,
-		mask:    mask,
-		data:    data,
-		modules: make([][]bool, size+2*quietZoneSize),
-		dirties: make([][]bool, size+2*quietZoneSize),
-		size:    size,
-	}
-
-	for i := range q.modules {
-		q.modules[i] = make([]bool, size+2*quietZoneSize)
-		q.dirties[i] = make([]bool, size+2*quietZoneSize)
+		version:   version,
+		ecl:       ecl,
+		mask:      mask,
+		data:      data,
+		width:     size,
+		height:    size,
+		quietZone: quietZoneSize,
 	}
-
+	q.initModules()
 	q.build()
 
 	return q
 }
 
+// initModules allocates modules and dirties including quiet zone
+func (q *QRCode) initModules() {
+	q.modules = make([][]bool, q.height+2*q.quietZone)
+	q.dirties = make([][]bool, q.height+2*q.quietZone)
+	for i := range q.modules {
+		q.modules[i] = make([]bool, q.width+2*q.quietZone)
+		q.dirties[i] = make([]bool, q.width+2*q.quietZone)
+	}
+}
+
+// Image returns image of QR code whose width is size pixels
+// height is scaled in proportion to width for rectangular symbols
 func (q *QRCode) Image(size int) image.Image {
-	realSize := q.size + 2*quietZoneSize
+	realWidth := q.width + 2*q.quietZone
+	realHeight := q.height + 2*q.quietZone
 
-	if size < realSize {
-		size = realSize
+	if size < realWidth {
+		size = realWidth
 	}
+	height := size * realHeight / realWidth
 
 	// Output image.
-	rect := image.Rectangle{Min: image.Point{0, 0}, Max: image.Point{size, size}}
+	rect := image.Rectangle{Min: image.Point{0, 0}, Max: image.Point{size, height}}
 
 	// Saves a few bytes to have them in this order
 	p := color.Palette([]color.Color{color.White, color.Black})
@@ -180,9 +276,12 @@ func (q *QRCode) Image(size int) image.Image {
 	bitmap := q.modules
 
 	// Map each image pixel to the nearest QR code module.
-	modulesPerPixel := float64(realSize) / float64(size)
-	for y := 0; y < size; y++ {
+	modulesPerPixel := float64(realWidth) / float64(size)
+	for y := 0; y < height; y++ {
 		y2 := int(float64(y) * modulesPerPixel)
+		if y2 >= realHeight {
+			y2 = realHeight - 1
+		}
 		for x := 0; x < size; x++ {
 			x2 := int(float64(x) * modulesPerPixel)
 
@@ -214,10 +313,13 @@ func (q *QRCode) PNG(size int) ([]byte, error) {
 func (q *QRCode) build() {
 	q.addFinderPatterns()
 	q.addSeparatorPattern()
+	// alignment patterns are added before timing patterns to find overlaps with finder patterns by dirties
+	q.addAlignmentPatterns()
 	q.addTimingPatterns()
 	// NOTE: format info is added after applying mask on JIS 7.1 section
 	//       but format info is added before applying mask here because dirties should be marked before adding data
 	q.addFormatInfo()
+	q.addVersionInfo()
 	q.addData()
 }
 
@@ -226,10 +328,10 @@ func (q *QRCode) addFinderPatterns() {
 	q.add2dPattern(0, 0, finderPattern)
 
 	// top right
-	q.add2dPattern(q.size-finderPatternSize, 0, finderPattern)
+	q.add2dPattern(q.width-finderPatternSize, 0, finderPattern)
 
 	// bottom left
-	q.add2dPattern(0, q.size-finderPatternSize, finderPattern)
+	q.add2dPattern(0, q.height-finderPatternSize, finderPattern)
 }
 
 func (q *QRCode) addSeparatorPattern() {
@@ -239,14 +341,28 @@ func (q *QRCode) addSeparatorPattern() {
 	q.add2dPattern(0, finderPatternSize, separatorHorizontalPattern)
 
 	// top right vertical
-	q.add2dPattern(q.size-finderPatternSize-1, 0, separatorVerticalPattern)
+	q.add2dPattern(q.width-finderPatternSize-1, 0, separatorVerticalPattern)
 	// top right horizontal
-	q.add2dPattern(q.size-finderPatternSize-1, finderPatternSize, separatorHorizontalPattern)
+	q.add2dPattern(q.width-finderPatternSize-1, finderPatternSize, separatorHorizontalPattern)
 
 	// bottom left vertical
-	q.add2dPattern(finderPatternSize, q.size-finderPatternSize-1, separatorVerticalPattern)
+	q.add2dPattern(finderPatternSize, q.height-finderPatternSize-1, separatorVerticalPattern)
 	// bottom left horizontal
-	q.add2dPattern(0, q.size-finderPatternSize-1, separatorHorizontalPattern)
+	q.add2dPattern(0, q.height-finderPatternSize-1, separatorHorizontalPattern)
+}
+
+func (q *QRCode) addAlignmentPatterns() {
+	centers := alignmentPatternCenters[q.version-1]
+
+	for _, y := range centers {
+		for _, x := range centers {
+			// alignment pattern must not overlap finder patterns and separators
+			if q.isDirty(x, y) {
+				continue
+			}
+			q.add2dPattern(x-alignmentPatternSize/2, y-alignmentPatternSize/2, alignmentPattern)
+		}
+	}
 }
 
 func (q *QRCode) addTimingPatterns() {
@@ -254,7 +370,7 @@ func (q *QRCode) addTimingPatterns() {
 	v := true
 
 	// start of timing pattern: finder pattern size + separator size (1)
-	for i := finderPatternSize + 1; i < q.size-finderPatternSize-1; i++ {
+	for i := finderPatternSize + 1; i < q.width-finderPatternSize-1; i++ {
 		// horizontal direction
 		q.add(i, finderPatternSize-1, v)
 		// vertical direction
@@ -265,63 +381,83 @@ func (q *QRCode) addTimingPatterns() {
 }
 
 func (q *QRCode) addData() {
+	q.forEachDataModule(q.data.Length(), func(i, x, y int) {
+		mask := calculateMask(x, y, q.maskPattern())
+		// != is equivalent to XOR.
+		q.add(x, y, mask != q.data.GetValue(i))
+	})
+}
+
+// forEachDataModule calls f with positions of n data modules in zigzag order from bottom right
+// function patterns must be added before calling it, because dirty modules are skipped
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.7.3
+func (q *QRCode) forEachDataModule(n int, f func(i, x, y int)) {
 	// when dx is  0, position is right
 	// when dx is -1, position is left
 	dx := 0
 
 	// start from bottom right
-	x := q.size - 1
-	y := q.size - 1
+	// the right edge of rMQR is timing pattern and finder sub pattern, so placement starts from the next column
+	x := q.width - 1
+	if q.kind == kindRMQR {
+		x--
+	}
+	y := q.height - 1
 
 	// direction
 	direction := up
 
-	for i := 0; i < q.data.Length(); i++ {
-		mask := calculateMask(x+dx, y, q.mask)
-		// != is equivalent to XOR.
-		q.add(x+dx, y, mask != q.data.GetValue(i))
-
-		if i == q.data.Length()-1 {
-			break
-		}
-
-		for {
-			if dx == 0 {
-				// next position is left
-				dx = -1
+	// next moves to the next position in zigzag order
+	next := func() {
+		if dx == 0 {
+			// next position is left
+			dx = -1
+		} else {
+			// next position is right
+			dx = 0
+
+			if direction == up {
+				if y > 0 {
+					y--
+				} else {
+					// if y is top, change direction
+					direction = down
+					x -= 2
+				}
 			} else {
-				// next position is right
-				dx = 0
-
-				if direction == up {
-					if y > 0 {
-						y--
-					} else {
-						// if y is top, change direction
-						direction = down
-						x -= 2
-					}
+				if y < q.height-1 {
+					y++
 				} else {
-					if y < q.size-1 {
-						y++
-					} else {
-						// if y is bottom, change direction
-						direction = up
-						x -= 2
-					}
+					// if y is bottom, change direction
+					direction = up
+					x -= 2
 				}
 			}
+		}
 
-			// column 6 cannot be write and need to skip
-			if x == 6 {
-				x--
-			}
+		// column 6 cannot be write and need to skip
+		// Micro QR code and rMQR have timing pattern on column 0, so they do not need to skip
+		if x == 6 && q.kind == kindQR {
+			x--
+		}
+	}
 
-			if !q.isDirty(x+dx, y) {
-				// break if next position is not dirty
-				break
-			}
-			// if next position is dirty, tries to find next not dirty position
+	// bottom right of rMQR is finder sub pattern
+	for q.isDirty(x+dx, y) {
+		next()
+	}
+
+	for i := 0; i < n; i++ {
+		f(i, x+dx, y)
+
+		if i == n-1 {
+			break
+		}
+
+		// if next position is dirty, tries to find next not dirty position
+		next()
+		for q.isDirty(x+dx, y) {
+			next()
 		}
 	}
 }
@@ -346,12 +482,12 @@ func (q *QRCode) addVerticalFormatInfo(fi *bitset.BitSet) {
 		q.add(finderPatternSize+1, i+1, fi.GetValue(last-i))
 	}
 
-	// (finderPatternSize+1, q.size-finderPatternSize-1) is black
-	q.add(finderPatternSize+1, q.size-finderPatternSize-1, true)
+	// (finderPatternSize+1, q.height-finderPatternSize-1) is black
+	q.add(finderPatternSize+1, q.height-finderPatternSize-1, true)
 
 	// Bits 8-14
 	for i := 8; i <= 14; i++ {
-		q.add(finderPatternSize+1, q.size-finderPatternSize-8+i, fi.GetValue(last-i))
+		q.add(finderPatternSize+1, q.height-finderPatternSize-8+i, fi.GetValue(last-i))
 	}
 }
 
@@ -359,7 +495,7 @@ func (q *QRCode) addHorizontalFormatInfo(fi *bitset.BitSet) {
 	last := formatInfoLength - 1
 	// Bits 0-7
 	for i := 0; i <= 7; i++ {
-		q.add(q.size-i-1, finderPatternSize+1, fi.GetValue(last-i))
+		q.add(q.width-i-1, finderPatternSize+1, fi.GetValue(last-i))
 	}
 
 	// Bits 8
@@ -373,6 +509,24 @@ func (q *QRCode) addHorizontalFormatInfo(fi *bitset.BitSet) {
 	}
 }
 
+func (q *QRCode) addVersionInfo() {
+	vi := VersionInfo(q.version)
+	if vi == nil {
+		return
+	}
+
+	last := versionInfoLength - 1
+	for i := 0; i <= last; i++ {
+		v := vi.GetValue(last - i)
+
+		// bottom left: 6 x 3 block above the bottom left separator
+		q.add(i/3, q.height-finderPatternSize-4+i%3, v)
+
+		// top right: 3 x 6 block on the left of the top right separator
+		q.add(q.width-finderPatternSize-4+i%3, i/3, v)
+	}
+}
+
 func (q *QRCode) penalty() int {
 	return q.penalty1() + q.penalty2() + q.penalty3() + q.penalty4()
 }
@@ -385,10 +539,10 @@ func (q *QRCode) penalty1Horizontal() int {
 	penalty := 0
 	penaltyWeight := 3
 
-	for y := 0; y < q.size; y++ {
+	for y := 0; y < q.height; y++ {
 		lastValue := q.get(0, y)
 		count := 1
-		for x := 1; x < q.size; x++ {
+		for x := 1; x < q.width; x++ {
 			v := q.get(x, y)
 
 			if v != lastValue {
@@ -411,10 +565,10 @@ func (q *QRCode) penalty1Vertical() int {
 	penalty := 0
 	penaltyWeight := 3
 
-	for x := 0; x < q.size; x++ {
+	for x := 0; x < q.width; x++ {
 		lastValue := q.get(x, 0)
 		count := 1
-		for y := 1; y < q.size; y++ {
+		for y := 1; y < q.height; y++ {
 			v := q.get(x, y)
 
 			if v != lastValue {
@@ -437,8 +591,8 @@ func (q *QRCode) penalty2() int {
 	count := 0
 	penaltyWeight2 := 3
 
-	for y := 1; y < q.size; y++ {
-		for x := 1; x < q.size; x++ {
+	for y := 1; y < q.height; y++ {
+		for x := 1; x < q.width; x++ {
 			topLeft := q.get(x-1, y-1)
 			above := q.get(x, y-1)
 			left := q.get(x-1, y)
@@ -455,10 +609,10 @@ func (q *QRCode) penalty2() int {
 func (q *QRCode) penalty3() int {
 	penaltyWeight3 := 40
 
-	for y := 0; y < q.size; y++ {
+	for y := 0; y < q.height; y++ {
 		var bitBuffer uint16 = 0x00
 
-		for x := 0; x < q.size; x++ {
+		for x := 0; x < q.width; x++ {
 			bitBuffer <<= 1
 			if v := q.get(x, y); v {
 				bitBuffer |= 1
@@ -470,17 +624,17 @@ func (q *QRCode) penalty3() int {
 			case 0x05d, 0x5d0:
 				return penaltyWeight3
 			default:
-				if x == q.size-1 && (bitBuffer&0x7f) == 0x5d {
+				if x == q.width-1 && (bitBuffer&0x7f) == 0x5d {
 					return penaltyWeight3
 				}
 			}
 		}
 	}
 
-	for x := 0; x < q.size; x++ {
+	for x := 0; x < q.width; x++ {
 		var bitBuffer uint16 = 0x00
 
-		for y := 0; y < q.size; y++ {
+		for y := 0; y < q.height; y++ {
 			bitBuffer <<= 1
 			if v := q.get(x, y); v {
 				bitBuffer |= 1
@@ -492,7 +646,7 @@ func (q *QRCode) penalty3() int {
 			case 0x05d, 0x5d0:
 				return penaltyWeight3
 			default:
-				if y == q.size-1 && (bitBuffer&0x7f) == 0x5d {
+				if y == q.height-1 && (bitBuffer&0x7f) == 0x5d {
 					return penaltyWeight3
 				}
 			}
@@ -504,11 +658,11 @@ func (q *QRCode) penalty3() int {
 
 func (q *QRCode) penalty4() int {
 	penaltyWeight4 := 10
-	numModules := q.size * q.size
+	numModules := q.width * q.height
 	numDarkModules := 0
 
-	for x := 0; x < q.size; x++ {
-		for y := 0; y < q.size; y++ {
+	for x := 0; x < q.width; x++ {
+		for y := 0; y < q.height; y++ {
 			if v := q.get(x, y); v {
 				numDarkModules++
 			}
@@ -533,16 +687,16 @@ func (q *QRCode) add2dPattern(x int, y int, pattern [][]bool) {
 }
 
 func (q *QRCode) add(x int, y int, v bool) {
-	q.modules[y+quietZoneSize][x+quietZoneSize] = v
-	q.dirties[y+quietZoneSize][x+quietZoneSize] = true
+	q.modules[y+q.quietZone][x+q.quietZone] = v
+	q.dirties[y+q.quietZone][x+q.quietZone] = true
 }
 
 func (q *QRCode) get(x int, y int) bool {
-	return q.modules[y+quietZoneSize][x+quietZoneSize]
+	return q.modules[y+q.quietZone][x+q.quietZone]
 }
 
 func (q *QRCode) isDirty(x, y int) bool {
-	return q.dirties[y+quietZoneSize][x+quietZoneSize]
+	return q.dirties[y+q.quietZone][x+q.quietZone]
 }
 
 func calculateMask(x, y int, mask uint8) bool {
@@ -0,0 +1,166 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ksrnnb/qrcode/bitset"
+)
+
+func TestAddAlignmentPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		centers [][2]int
+	}{
+		{
+			name:    "version 1 has no alignment pattern",
+			version: 1,
+		},
+		{
+			name:    "version 2 has one alignment pattern",
+			version: 2,
+			centers: [][2]int{{18, 18}},
+		},
+		{
+			name:    "version 7 has six alignment patterns",
+			version: 7,
+			centers: [][2]int{{22, 6}, {6, 22}, {22, 22}, {38, 22}, {22, 38}, {38, 38}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			size := symbolSize(test.version)
+			q := &QRCode{
+				version:   test.version,
+				data:      bitset.NewBitSet(0),
+				width:     size,
+				height:    size,
+				quietZone: quietZoneSize,
+			}
+			q.initModules()
+			q.addFinderPatterns()
+			q.addSeparatorPattern()
+			q.addAlignmentPatterns()
+
+			for _, c := range test.centers {
+				x, y := c[0], c[1]
+				if !q.get(x, y) || q.get(x-1, y) || q.get(x+1, y-1) || !q.get(x-2, y+2) || !q.get(x+2, y-2) {
+					t.Errorf("alignment pattern is expected at (%d, %d)\n", x, y)
+				}
+			}
+			// finder patterns must not be overwritten
+			for _, origin := range [][2]int{{0, 0}, {size - finderPatternSize, 0}, {0, size - finderPatternSize}} {
+				for dy, row := range finderPattern {
+					for dx, want := range row {
+						if q.get(origin[0]+dx, origin[1]+dy) != want {
+							t.Errorf("finder pattern is overwritten at (%d, %d)\n", origin[0]+dx, origin[1]+dy)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewFromSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		ecl      ErrorCorrectionLevel
+		segments []Segment
+		want     []byte
+		wantErr  bool
+	}{
+		{
+			name: "digits in 8 bits byte mode",
+			ecl:  ECL_Medium,
+			segments: []Segment{
+				{Mode: EightBits, Data: "123"},
+			},
+			want: []byte{0x40, 0x33, 0x13, 0x23, 0x30, 0xEC},
+		},
+		{
+			name: "multiple segments",
+			ecl:  ECL_Medium,
+			segments: []Segment{
+				{Mode: AlphaNumeric, Data: "A"},
+				{Mode: Numeric, Data: "1"},
+			},
+			// 0010 000000001 001010 0001 0000000001 0001 0000
+			want: []byte{0x20, 0x09, 0x42, 0x00, 0x88, 0x00, 0xEC},
+		},
+		{
+			name: "ECI and 8 bits byte",
+			ecl:  ECL_Medium,
+			segments: []Segment{
+				ECISegment(ECI_ShiftJIS),
+				{Mode: EightBits, Data: "\x93\x5f"},
+			},
+			// 0111 00010100 0100 00000010 10010011 01011111 0000
+			want: []byte{0x71, 0x44, 0x02, 0x93, 0x5f, 0x00, 0xEC},
+		},
+		{
+			name: "invalid ECI assignment number",
+			ecl:  ECL_Medium,
+			segments: []Segment{
+				{Mode: ECI, Data: "1000000"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "letters in numeric mode",
+			ecl:  ECL_Medium,
+			segments: []Segment{
+				{Mode: Numeric, Data: "12a"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "lower case letters in alpha numeric mode",
+			ecl:  ECL_Medium,
+			segments: []Segment{
+				{Mode: AlphaNumeric, Data: "abc"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ascii in kanji mode",
+			ecl:  ECL_Medium,
+			segments: []Segment{
+				{Mode: Kanji, Data: "点A"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "too long",
+			ecl:  ECL_Highest,
+			segments: []Segment{
+				{Mode: EightBits, Data: strings.Repeat("a", 1274)},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := NewFromSegments(test.ecl, test.segments...)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("error is expected, but got nil\n")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			for i, want := range test.want {
+				if q.data.ByteAt(i) != want {
+					t.Errorf("want %#x, but got %#x at index: %d\n", want, q.data.ByteAt(i), i)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package reedsolomon
+
+import (
+	"fmt"
+
+	"github.com/ksrnnb/qrcode/bitset"
+	"github.com/ksrnnb/qrcode/reedsolomon/galoisfield"
+)
+
+// Decode corrects errors of codewords which consist of data codewords and ecwords error correction codewords
+// it returns corrected data codewords and number of corrected codewords
+// up to ecwords / 2 errors can be corrected, and it returns error if codewords have more errors
+func Decode(codewords []byte, ecwords int) ([]byte, int, error) {
+	return DecodeWithErasures(codewords, ecwords, nil)
+}
+
+// DecodeWithErasures corrects errors and erasures of codewords
+// erasures are indices of codewords which are known to be unreliable, such as modules covered by a logo
+// e errors and f erasures can be corrected when 2e + f <= ecwords
+func DecodeWithErasures(codewords []byte, ecwords int, erasures []int) ([]byte, int, error) {
+	if ecwords < 2 || ecwords >= len(codewords) {
+		return nil, 0, fmt.Errorf("count of error correction codewords must be between 2 and %d, but got %d", len(codewords)-1, ecwords)
+	}
+	// codeword length is limited by number of nonzero elements of GF(2^8)
+	if len(codewords) > 255 {
+		return nil, 0, fmt.Errorf("codewords must be 255 or less, but got %d", len(codewords))
+	}
+	erasureLocator, erased, err := newErasureLocator(erasures, len(codewords))
+	if err != nil {
+		return nil, 0, err
+	}
+	if erased > ecwords {
+		return nil, 0, fmt.Errorf("%d erasures cannot be corrected by %d error correction codewords", erased, ecwords)
+	}
+
+	received := receivedPolynomial(codewords)
+	syndromes, ok := calculateSyndromes(received, ecwords)
+	if ok {
+		return dataCodeWords(codewords, ecwords), 0, nil
+	}
+
+	locator, err := berlekampMassey(syndromes, erasureLocator, erased, ecwords)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	positions, err := chienSearch(locator, len(codewords))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	magnitudes := forney(syndromes, locator, positions, ecwords)
+
+	corrected := make([]byte, len(codewords))
+	copy(corrected, codewords)
+	count := 0
+	for i, p := range positions {
+		if magnitudes[i].IsZero() {
+			continue
+		}
+		// x^p corresponds to codeword at index len - 1 - p
+		corrected[len(codewords)-1-p] ^= byte(magnitudes[i])
+		count++
+	}
+
+	if _, ok := calculateSyndromes(receivedPolynomial(corrected), ecwords); !ok {
+		return nil, 0, fmt.Errorf("codewords have too many errors to be corrected")
+	}
+	return dataCodeWords(corrected, ecwords), count, nil
+}
+
+// receivedPolynomial converts codewords to polynomial whose first codeword is coefficient of max degree
+func receivedPolynomial(codewords []byte) galoisfield.Polynomial {
+	bs := bitset.NewBitSet(len(codewords) * 8)
+	bs.SetBytes(codewords)
+	return galoisfield.NewPolynomial(bs)
+}
+
+func dataCodeWords(codewords []byte, ecwords int) []byte {
+	data := make([]byte, len(codewords)-ecwords)
+	copy(data, codewords)
+	return data
+}
+
+// calculateSyndromes returns syndrome polynomial S(x) = S_0 + S_1*x + ... + S_(ecwords-1)*x^(ecwords-1)
+// S_i is r(α^i) because roots of generator polynomial are α^0 to α^(ecwords-1)
+// it returns true if all syndromes are zero, which means codewords have no error
+func calculateSyndromes(received galoisfield.Polynomial, ecwords int) (galoisfield.Polynomial, bool) {
+	syndromes := galoisfield.NewMonomial(0, 0)
+	noError := true
+	for i := 0; i < ecwords; i++ {
+		s := received.Evaluate(galoisfield.ElementByExponentOfAlpha(i))
+		if !s.IsZero() {
+			noError = false
+		}
+		syndromes = syndromes.Add(galoisfield.NewMonomial(s, i))
+	}
+	return syndromes, noError
+}
+
+// newErasureLocator returns erasure locator polynomial Γ(x) = (1 + Y_1*x)(1 + Y_2*x)...(1 + Y_f*x) and number of erasures f
+// Y_k is α^p when erasure is at codeword index length - 1 - p
+func newErasureLocator(erasures []int, length int) (galoisfield.Polynomial, int, error) {
+	locator := galoisfield.NewMonomial(1, 0)
+	erased := make(map[int]bool, len(erasures))
+	for _, index := range erasures {
+		if index < 0 || index >= length {
+			return galoisfield.Polynomial{}, 0, fmt.Errorf("erasure position must be between 0 and %d, but got %d", length-1, index)
+		}
+		// the same position is counted once
+		if erased[index] {
+			continue
+		}
+		erased[index] = true
+
+		y := galoisfield.ElementByExponentOfAlpha(length - 1 - index)
+		locator = locator.Multiply(galoisfield.NewMonomial(1, 0).Add(galoisfield.NewMonomial(y, 1)))
+	}
+	return locator, len(erased), nil
+}
+
+// berlekampMassey returns errata locator polynomial Λ(x) = (1 + X_1*x)(1 + X_2*x)...(1 + X_v*x)
+// X_k is α^p when error or erasure is at x^p
+// the locator starts from erasure locator of f erasures, so that it finds only locations of errors
+func berlekampMassey(syndromes, erasureLocator galoisfield.Polynomial, f int, ecwords int) (galoisfield.Polynomial, error) {
+	locator := erasureLocator
+	previous := erasureLocator
+	length := f
+	shift := 1
+	previousDiscrepancy := galoisfield.Element(1)
+
+	for n := f; n < ecwords; n++ {
+		// discrepancy is Λ_0*S_n + Λ_1*S_(n-1) + ... + Λ_L*S_(n-L)
+		var discrepancy galoisfield.Element
+		for i := 0; i <= degree(locator); i++ {
+			discrepancy = discrepancy.Add(locator.Coefficient(i).Multiply(syndromes.Coefficient(n - i)))
+		}
+		if discrepancy.IsZero() {
+			shift++
+			continue
+		}
+
+		coefficient := discrepancy.Divide(previousDiscrepancy)
+		next := locator.Add(previous.Multiply(galoisfield.NewMonomial(coefficient, shift)))
+		if 2*length <= n+f {
+			previous = locator
+			length = n + 1 + f - length
+			previousDiscrepancy = discrepancy
+			shift = 1
+		} else {
+			shift++
+		}
+		locator = next
+	}
+
+	// number of errors e is length - f, and 2e + f must not be over ecwords
+	if 2*length-f > ecwords || degree(locator) != length {
+		return galoisfield.Polynomial{}, fmt.Errorf("codewords have too many errors to be corrected")
+	}
+	return locator, nil
+}
+
+// chienSearch returns degrees p of errors where Λ(α^-p) is zero
+func chienSearch(locator galoisfield.Polynomial, length int) ([]int, error) {
+	var positions []int
+	for p := 0; p < length; p++ {
+		if locator.Evaluate(galoisfield.ElementByExponentOfAlpha(255 - p%255)).IsZero() {
+			positions = append(positions, p)
+		}
+	}
+
+	// all roots must be found in codewords
+	if len(positions) != degree(locator) {
+		return nil, fmt.Errorf("codewords have too many errors to be corrected")
+	}
+	return positions, nil
+}
+
+// forney returns error magnitudes at positions of errors and erasures
+// e_k = X_k * Ω(X_k^-1) / Λ'(X_k^-1), where error evaluator Ω(x) = S(x)Λ(x) mod x^ecwords
+func forney(syndromes, locator galoisfield.Polynomial, positions []int, ecwords int) []galoisfield.Element {
+	product := syndromes.Multiply(locator)
+	evaluator := galoisfield.NewMonomial(0, 0)
+	for i := 0; i < ecwords; i++ {
+		evaluator = evaluator.Add(galoisfield.NewMonomial(product.Coefficient(i), i))
+	}
+	derivative := locator.Derivative()
+
+	magnitudes := make([]galoisfield.Element, len(positions))
+	for i, p := range positions {
+		x := galoisfield.ElementByExponentOfAlpha(p)
+		xInverse := galoisfield.ElementByExponentOfAlpha(255 - p%255)
+
+		denominator := derivative.Evaluate(xInverse)
+		if denominator.IsZero() {
+			continue
+		}
+		magnitudes[i] = x.Multiply(evaluator.Evaluate(xInverse)).Divide(denominator)
+	}
+	return magnitudes
+}
+
+// degree returns max degree of polynomial
+func degree(f galoisfield.Polynomial) int {
+	terms := f.Terms()
+	for i := len(terms) - 1; i >= 0; i-- {
+		if !terms[i].IsZero() {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,177 @@
+package reedsolomon
+
+import (
+	"testing"
+
+	"github.com/ksrnnb/qrcode/bitset"
+)
+
+func TestDecode(t *testing.T) {
+	data := []byte{
+		0b00010000, 0b00100000, 0b00001100, 0b01010110, 0b01100001, 0b10000000, 0b11101100, 0b00010001, 0b11101100, 0b00010001, 0b11101100, 0b00010001, 0b11101100, 0b00010001, 0b11101100, 0b00010001,
+	}
+	ecwords := 10
+
+	tests := []struct {
+		name      string
+		errors    map[int]byte
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name:      "an error in data codewords",
+			errors:    map[int]byte{3: 0xFF},
+			wantCount: 1,
+		},
+		{
+			name:      "an error in error correction codewords",
+			errors:    map[int]byte{20: 0x01},
+			wantCount: 1,
+		},
+		{
+			name:      "errors up to capacity",
+			errors:    map[int]byte{0: 0x12, 5: 0x34, 11: 0x56, 17: 0x78, 25: 0x9A},
+			wantCount: 5,
+		},
+		{
+			name:    "too many errors",
+			errors:  map[int]byte{0: 0x12, 1: 0x34, 2: 0x56, 3: 0x78, 4: 0x9A, 5: 0xBC, 6: 0xDE, 7: 0xF0},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			codewords := encodeBytes(data, ecwords)
+			for i, e := range test.errors {
+				codewords[i] ^= e
+			}
+
+			got, count, err := Decode(codewords, ecwords)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("error is expected, but got nil\n")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if count != test.wantCount {
+				t.Errorf("expected %d corrections, got %d\n", test.wantCount, count)
+			}
+			if string(got) != string(data) {
+				t.Errorf("want %v, but got %v\n", data, got)
+			}
+		})
+	}
+}
+
+func TestDecodeWithErasures(t *testing.T) {
+	data := []byte{
+		0b00010000, 0b00100000, 0b00001100, 0b01010110, 0b01100001, 0b10000000, 0b11101100, 0b00010001, 0b11101100, 0b00010001, 0b11101100, 0b00010001, 0b11101100, 0b00010001, 0b11101100, 0b00010001,
+	}
+	ecwords := 10
+
+	tests := []struct {
+		name      string
+		errors    map[int]byte
+		erasures  []int
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "erasures up to count of error correction codewords",
+			errors:    map[int]byte{0: 0x01, 2: 0x02, 4: 0x03, 6: 0x04, 8: 0x05, 10: 0x06, 12: 0x07, 14: 0x08, 16: 0x09, 18: 0x0A},
+			erasures:  []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18},
+			wantCount: 10,
+		},
+		{
+			name:      "errors and erasures",
+			errors:    map[int]byte{1: 0x11, 3: 0x22, 5: 0x33, 7: 0x44, 9: 0x55, 23: 0x66, 25: 0x77},
+			erasures:  []int{1, 3, 5, 7},
+			wantCount: 7,
+		},
+		{
+			name:      "erased codewords are correct",
+			errors:    map[int]byte{3: 0x22},
+			erasures:  []int{1, 3, 5},
+			wantCount: 1,
+		},
+		{
+			name:      "duplicated erasures",
+			errors:    map[int]byte{3: 0x22},
+			erasures:  []int{3, 3},
+			wantCount: 1,
+		},
+		{
+			name:     "errors and erasures over capacity",
+			errors:   map[int]byte{1: 0x11, 3: 0x22, 5: 0x33, 7: 0x44, 9: 0x55, 23: 0x66, 25: 0x77, 24: 0x88},
+			erasures: []int{1, 3, 5, 7},
+			wantErr:  true,
+		},
+		{
+			name:     "too many erasures",
+			erasures: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			wantErr:  true,
+		},
+		{
+			name:     "erasure out of range",
+			erasures: []int{26},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			codewords := encodeBytes(data, ecwords)
+			for i, e := range test.errors {
+				codewords[i] ^= e
+			}
+
+			got, count, err := DecodeWithErasures(codewords, ecwords, test.erasures)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("error is expected, but got nil\n")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if count != test.wantCount {
+				t.Errorf("expected %d corrections, got %d\n", test.wantCount, count)
+			}
+			if string(got) != string(data) {
+				t.Errorf("want %v, but got %v\n", data, got)
+			}
+		})
+	}
+}
+
+func TestDecode_InvalidLength(t *testing.T) {
+	if _, _, err := Decode([]byte{1, 2, 3}, 3); err == nil {
+		t.Errorf("error is expected, but got nil\n")
+	}
+	if _, _, err := Decode(make([]byte, 256), 10); err == nil {
+		t.Errorf("error is expected, but got nil\n")
+	}
+}
+
+// encodeBytes returns data codewords followed by error correction codewords
+func encodeBytes(data []byte, ecwords int) []byte {
+	bs := bitset.NewBitSet(len(data) * 8)
+	bs.SetBytes(data)
+	encoded := Encode(bs, ecwords)
+
+	codewords := make([]byte, len(data)+ecwords)
+	for i := range codewords {
+		codewords[i] = encoded.ByteAt(i)
+	}
+	return codewords
+}
@@ -161,3 +161,36 @@ func (f Polynomial) normalize() Polynomial {
 	f.terms = f.terms[0 : newMaxDegree+1]
 	return f
 }
+
+// Coefficient returns coefficient of x^degree
+// it returns zero if degree is over max degree
+func (f Polynomial) Coefficient(degree int) Element {
+	if degree < 0 || degree >= len(f.terms) {
+		return 0
+	}
+	return f.terms[degree]
+}
+
+// Evaluate returns f(x) by Horner's method
+func (f Polynomial) Evaluate(x Element) Element {
+	var result Element
+	for i := f.maxDegree(); i >= 0; i-- {
+		result = result.Multiply(x).Add(f.terms[i])
+	}
+	return result
+}
+
+// Derivative returns formal derivative f'(x)
+// terms of even degree vanish because 2 = 0 in GF(2^8)
+func (f Polynomial) Derivative() Polynomial {
+	if f.maxDegree() == 0 {
+		return zeroPolynomial
+	}
+	derivative := Polynomial{
+		terms: make([]Element, f.maxDegree()),
+	}
+	for i := 1; i <= f.maxDegree(); i += 2 {
+		derivative.terms[i-1] = f.terms[i]
+	}
+	return derivative.normalize()
+}
@@ -193,3 +193,76 @@ func TestPolynomial_Remainder(t *testing.T) {
 		})
 	}
 }
+
+func TestPolynomial_Evaluate(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Polynomial
+		x    Element
+		want Element
+	}{
+		{
+			name: "evaluate at α^1",
+			f: Polynomial{
+				terms: []Element{
+					0b0111_0100, 0b0001_0000, 0b0001_1101, // α^10 + α^4*x^1 + α^8*x^2
+				},
+			},
+			x:    0b0000_0010, // α^1
+			want: 0b0010_0000, // α^10 + α^5 + α^10 = α^5
+		},
+		{
+			name: "evaluate at zero",
+			f: Polynomial{
+				terms: []Element{
+					0b0111_0100, 0b0001_0000, 0b0001_1101, // α^10 + α^4*x^1 + α^8*x^2
+				},
+			},
+			x:    0,
+			want: 0b0111_0100, // α^10
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.f.Evaluate(test.x); got != test.want {
+				t.Errorf("want %b, but got %b\n", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPolynomial_Derivative(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Polynomial
+		want Polynomial
+	}{
+		{
+			name: "terms of even degree vanish",
+			f:    Polynomial{terms: []Element{1, 2, 3, 4, 5}},
+			want: Polynomial{terms: []Element{2, 0, 4}},
+		},
+		{
+			name: "constant",
+			f:    Polynomial{terms: []Element{7}},
+			want: zeroPolynomial,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := test.f.Derivative()
+			if p.maxDegree() != test.want.maxDegree() {
+				t.Errorf("want degree is %d, but got %d\n", test.want.maxDegree(), p.maxDegree())
+				return
+			}
+			for i, v := range test.want.terms {
+				if p.terms[i] != v {
+					t.Errorf("want %+v, but got %+v\n", test.want, p)
+					break
+				}
+			}
+		})
+	}
+}
@@ -14,7 +14,13 @@ func Encode(bs *bitset.BitSet, ecwords int) *bitset.BitSet {
 	remainder := f.Remainder(g)
 
 	result := bs.Clone()
-	result.SetBytes(remainder.ToByte())
+
+	// remainder has less terms than ecwords when terms of higher degree are zero
+	ecc := remainder.ToByte()
+	for i := len(ecc); i < ecwords; i++ {
+		result.SetByte(0)
+	}
+	result.SetBytes(ecc)
 
 	return result
 }
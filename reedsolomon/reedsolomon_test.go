@@ -36,6 +36,12 @@ func TestEncode(t *testing.T) {
 				0b10100101, 0b00100100, 0b11010100, 0b11000001, 0b11101101, 0b00110110, 0b11000111, 0b10000111, 0b00101100, 0b01010101,
 			},
 		},
+		{
+			name:    "remainder is zero",
+			ecwords: 7,
+			data:    []byte{0, 0, 0},
+			want:    []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
 	}
 
 	for _, test := range tests {
@@ -43,6 +49,10 @@ func TestEncode(t *testing.T) {
 			bs := bitset.NewBitSet(len(test.data) * 8)
 			bs.SetBytes(test.data)
 			result := Encode(bs, test.ecwords)
+			if result.Length() != len(test.want)*8 {
+				t.Errorf("length is expected %d, but got %d\n", len(test.want)*8, result.Length())
+				return
+			}
 			for i, want := range test.want {
 				if result.ByteAt(i) != want {
 					t.Errorf("want %d, but got %d at index: %d\n", want, result.ByteAt(i), i)
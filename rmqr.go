@@ -0,0 +1,358 @@
+package qrcode
+
+import (
+	"fmt"
+
+	"github.com/ksrnnb/qrcode/bitset"
+)
+
+const (
+	rmqrQuietZoneSize = 2
+
+	rmqrModeIndicatorBits = 3
+	rmqrTerminatorBits    = 3
+
+	// rMQR always uses mask pattern 4 of QR code
+	rmqrMaskPattern = 4
+
+	rmqrFormatInfoLength = 18
+	// generator polynomial of (18, 6) BCH code: x^12 + x^11 + x^10 + x^9 + x^8 + x^5 + x^2 + 1
+	rmqrFormatGenerator = 0x1F25
+	// format information is masked by different patterns on the finder pattern side and the finder sub pattern side
+	rmqrLeftFormatMask  = 0x1FAB2
+	rmqrRightFormatMask = 0x20A7B
+
+	finderSubPatternSize = 5
+)
+
+// rmqrSymbol shows size and capacity of rMQR symbol
+// data codewords are split into blocks evenly, and the latter blocks have one more codeword if they cannot be split evenly
+type rmqrSymbol struct {
+	height         int
+	width          int
+	remainderBits  int
+	totalCodeWords int
+
+	// data codewords and number of blocks of level M and H
+	mDataCodeWords int
+	mBlocks        int
+	hDataCodeWords int
+	hBlocks        int
+
+	// character count indicator bits of numeric, alpha numeric, 8 bits byte and kanji mode
+	characterCountBits [4]int
+}
+
+// rmqrSymbols shows rMQR symbols in order of version indicator
+// reference: ISO/IEC 23941 : 2022 Table 3, Table 6 and Table 8
+var rmqrSymbols = []rmqrSymbol{
+	{height: 7, width: 43, remainderBits: 0, totalCodeWords: 13, mDataCodeWords: 6, mBlocks: 1, hDataCodeWords: 3, hBlocks: 1, characterCountBits: [4]int{4, 3, 3, 2}},
+	{height: 7, width: 59, remainderBits: 3, totalCodeWords: 21, mDataCodeWords: 12, mBlocks: 1, hDataCodeWords: 7, hBlocks: 1, characterCountBits: [4]int{5, 5, 4, 3}},
+	{height: 7, width: 77, remainderBits: 5, totalCodeWords: 32, mDataCodeWords: 20, mBlocks: 1, hDataCodeWords: 10, hBlocks: 1, characterCountBits: [4]int{6, 5, 5, 4}},
+	{height: 7, width: 99, remainderBits: 6, totalCodeWords: 44, mDataCodeWords: 28, mBlocks: 1, hDataCodeWords: 14, hBlocks: 1, characterCountBits: [4]int{7, 6, 5, 5}},
+	{height: 7, width: 139, remainderBits: 1, totalCodeWords: 68, mDataCodeWords: 44, mBlocks: 1, hDataCodeWords: 24, hBlocks: 2, characterCountBits: [4]int{7, 6, 6, 5}},
+	{height: 9, width: 43, remainderBits: 2, totalCodeWords: 21, mDataCodeWords: 12, mBlocks: 1, hDataCodeWords: 7, hBlocks: 1, characterCountBits: [4]int{5, 5, 4, 3}},
+	{height: 9, width: 59, remainderBits: 3, totalCodeWords: 33, mDataCodeWords: 21, mBlocks: 1, hDataCodeWords: 11, hBlocks: 1, characterCountBits: [4]int{6, 5, 5, 4}},
+	{height: 9, width: 77, remainderBits: 1, totalCodeWords: 49, mDataCodeWords: 31, mBlocks: 1, hDataCodeWords: 17, hBlocks: 2, characterCountBits: [4]int{7, 6, 5, 5}},
+	{height: 9, width: 99, remainderBits: 4, totalCodeWords: 66, mDataCodeWords: 42, mBlocks: 1, hDataCodeWords: 22, hBlocks: 2, characterCountBits: [4]int{7, 6, 6, 5}},
+	{height: 9, width: 139, remainderBits: 5, totalCodeWords: 99, mDataCodeWords: 63, mBlocks: 2, hDataCodeWords: 33, hBlocks: 3, characterCountBits: [4]int{8, 7, 6, 6}},
+	{height: 11, width: 27, remainderBits: 2, totalCodeWords: 15, mDataCodeWords: 7, mBlocks: 1, hDataCodeWords: 5, hBlocks: 1, characterCountBits: [4]int{4, 4, 3, 2}},
+	{height: 11, width: 43, remainderBits: 1, totalCodeWords: 31, mDataCodeWords: 19, mBlocks: 1, hDataCodeWords: 11, hBlocks: 1, characterCountBits: [4]int{6, 5, 5, 4}},
+	{height: 11, width: 59, remainderBits: 0, totalCodeWords: 47, mDataCodeWords: 31, mBlocks: 1, hDataCodeWords: 15, hBlocks: 2, characterCountBits: [4]int{7, 6, 5, 5}},
+	{height: 11, width: 77, remainderBits: 2, totalCodeWords: 67, mDataCodeWords: 43, mBlocks: 1, hDataCodeWords: 23, hBlocks: 2, characterCountBits: [4]int{7, 6, 6, 5}},
+	{height: 11, width: 99, remainderBits: 7, totalCodeWords: 89, mDataCodeWords: 57, mBlocks: 2, hDataCodeWords: 29, hBlocks: 2, characterCountBits: [4]int{8, 7, 6, 6}},
+	{height: 11, width: 139, remainderBits: 6, totalCodeWords: 132, mDataCodeWords: 84, mBlocks: 2, hDataCodeWords: 42, hBlocks: 3, characterCountBits: [4]int{8, 7, 7, 6}},
+	{height: 13, width: 27, remainderBits: 4, totalCodeWords: 21, mDataCodeWords: 12, mBlocks: 1, hDataCodeWords: 7, hBlocks: 1, characterCountBits: [4]int{5, 5, 4, 3}},
+	{height: 13, width: 43, remainderBits: 1, totalCodeWords: 41, mDataCodeWords: 27, mBlocks: 1, hDataCodeWords: 13, hBlocks: 1, characterCountBits: [4]int{6, 6, 5, 5}},
+	{height: 13, width: 59, remainderBits: 6, totalCodeWords: 60, mDataCodeWords: 38, mBlocks: 1, hDataCodeWords: 20, hBlocks: 2, characterCountBits: [4]int{7, 6, 6, 5}},
+	{height: 13, width: 77, remainderBits: 4, totalCodeWords: 85, mDataCodeWords: 53, mBlocks: 2, hDataCodeWords: 29, hBlocks: 2, characterCountBits: [4]int{7, 7, 6, 6}},
+	{height: 13, width: 99, remainderBits: 3, totalCodeWords: 113, mDataCodeWords: 73, mBlocks: 2, hDataCodeWords: 35, hBlocks: 3, characterCountBits: [4]int{8, 7, 7, 6}},
+	{height: 13, width: 139, remainderBits: 0, totalCodeWords: 166, mDataCodeWords: 106, mBlocks: 3, hDataCodeWords: 54, hBlocks: 4, characterCountBits: [4]int{8, 8, 7, 7}},
+	{height: 15, width: 43, remainderBits: 1, totalCodeWords: 51, mDataCodeWords: 33, mBlocks: 1, hDataCodeWords: 15, hBlocks: 2, characterCountBits: [4]int{7, 6, 6, 5}},
+	{height: 15, width: 59, remainderBits: 4, totalCodeWords: 74, mDataCodeWords: 48, mBlocks: 1, hDataCodeWords: 26, hBlocks: 2, characterCountBits: [4]int{7, 7, 6, 5}},
+	{height: 15, width: 77, remainderBits: 6, totalCodeWords: 103, mDataCodeWords: 67, mBlocks: 2, hDataCodeWords: 31, hBlocks: 3, characterCountBits: [4]int{8, 7, 7, 6}},
+	{height: 15, width: 99, remainderBits: 7, totalCodeWords: 136, mDataCodeWords: 88, mBlocks: 2, hDataCodeWords: 48, hBlocks: 4, characterCountBits: [4]int{8, 7, 7, 6}},
+	{height: 15, width: 139, remainderBits: 2, totalCodeWords: 199, mDataCodeWords: 127, mBlocks: 3, hDataCodeWords: 69, hBlocks: 5, characterCountBits: [4]int{9, 8, 7, 7}},
+	{height: 17, width: 43, remainderBits: 1, totalCodeWords: 61, mDataCodeWords: 39, mBlocks: 1, hDataCodeWords: 21, hBlocks: 2, characterCountBits: [4]int{7, 6, 6, 5}},
+	{height: 17, width: 59, remainderBits: 2, totalCodeWords: 88, mDataCodeWords: 56, mBlocks: 2, hDataCodeWords: 28, hBlocks: 2, characterCountBits: [4]int{8, 7, 6, 6}},
+	{height: 17, width: 77, remainderBits: 0, totalCodeWords: 122, mDataCodeWords: 78, mBlocks: 2, hDataCodeWords: 38, hBlocks: 3, characterCountBits: [4]int{8, 7, 7, 6}},
+	{height: 17, width: 99, remainderBits: 3, totalCodeWords: 160, mDataCodeWords: 100, mBlocks: 3, hDataCodeWords: 56, hBlocks: 4, characterCountBits: [4]int{8, 8, 7, 6}},
+	{height: 17, width: 139, remainderBits: 4, totalCodeWords: 232, mDataCodeWords: 152, mBlocks: 4, hDataCodeWords: 76, hBlocks: 6, characterCountBits: [4]int{9, 8, 8, 7}},
+}
+
+// rmqrAlignmentPatternColumns shows center columns of alignment patterns by width
+// reference: ISO/IEC 23941 : 2022 Table D.1
+var rmqrAlignmentPatternColumns = map[int][]int{
+	27:  {},
+	43:  {21},
+	59:  {19, 39},
+	77:  {25, 51},
+	99:  {23, 49, 75},
+	139: {27, 55, 83, 111},
+}
+
+// rmqrModeIndicators shows 3 bits mode indicators of rMQR
+// reference: ISO/IEC 23941 : 2022 Table 2
+var rmqrModeIndicators = map[ModeIndicator]int{
+	Numeric:      0b001,
+	AlphaNumeric: 0b010,
+	EightBits:    0b011,
+	Kanji:        0b100,
+	FNC1First:    0b101,
+	FNC1Second:   0b110,
+	ECI:          0b111,
+}
+
+// characterCountIndicatorBits returns character count indicator bits of the mode
+func (rs rmqrSymbol) characterCountIndicatorBits(mode ModeIndicator) int {
+	switch mode {
+	case Numeric:
+		return rs.characterCountBits[0]
+	case AlphaNumeric:
+		return rs.characterCountBits[1]
+	case EightBits:
+		return rs.characterCountBits[2]
+	case Kanji:
+		return rs.characterCountBits[3]
+	}
+	return 0
+}
+
+// headerBits returns headerBitsFunc of the symbol
+func (rs rmqrSymbol) headerBits() headerBitsFunc {
+	return func(mode ModeIndicator) (int, bool) {
+		ccBits := rs.characterCountIndicatorBits(mode)
+		if ccBits == 0 {
+			return 0, false
+		}
+		return rmqrModeIndicatorBits + ccBits, true
+	}
+}
+
+// dataCodeWords returns number of data codewords of the error correction level
+func (rs rmqrSymbol) dataCodeWords(ecl ErrorCorrectionLevel) int {
+	if ecl == ECL_Highest {
+		return rs.hDataCodeWords
+	}
+	return rs.mDataCodeWords
+}
+
+// ecBlocks returns error correction blocks of the error correction level
+func (rs rmqrSymbol) ecBlocks(ecl ErrorCorrectionLevel) []ecBlock {
+	count := rs.mBlocks
+	if ecl == ECL_Highest {
+		count = rs.hBlocks
+	}
+
+	total := rs.totalCodeWords / count
+	data := rs.dataCodeWords(ecl) / count
+	// blocks which have one more codeword
+	longer := rs.totalCodeWords % count
+
+	if longer == 0 {
+		return []ecBlock{{count: count, totalCodeWords: total, dataCodeWords: data}}
+	}
+	return []ecBlock{
+		{count: count - longer, totalCodeWords: total, dataCodeWords: data},
+		{count: longer, totalCodeWords: total + 1, dataCodeWords: data + 1},
+	}
+}
+
+// fits returns true if segments can be encoded in the symbol
+func (rs rmqrSymbol) fits(ecl ErrorCorrectionLevel, segments []Segment) bool {
+	bits := 0
+	for _, s := range segments {
+		ccBits := rs.characterCountIndicatorBits(s.Mode)
+		// number of characters must be expressed by character count indicator
+		if ccBits > 0 && characterCount(s.Mode, s.Data) >= 1<<ccBits {
+			return false
+		}
+		bits += rmqrModeIndicatorBits + ccBits + s.dataBitLength()
+	}
+	return bits <= rs.dataCodeWords(ecl)*8
+}
+
+// NewRMQR creates rMQR of the smallest area which can contain content
+// ecl must be ECL_Medium or ECL_Highest, because rMQR has only level M and H
+func NewRMQR(ecl ErrorCorrectionLevel, content string) (*QRCode, error) {
+	if ecl != ECL_Medium && ecl != ECL_Highest {
+		return nil, fmt.Errorf("error correction level of rMQR must be M or H")
+	}
+
+	index := -1
+	var segments []Segment
+	for i, symbol := range rmqrSymbols {
+		s := optimizeSymbolSegments(content, symbol.headerBits(), encodeUTF8, false)
+		if !symbol.fits(ecl, s) {
+			continue
+		}
+
+		// the symbol of the smaller height is used if areas are the same
+		if index < 0 || symbol.width*symbol.height < rmqrSymbols[index].width*rmqrSymbols[index].height {
+			index = i
+			segments = s
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("content is too long to be encoded in rMQR")
+	}
+
+	data := encodeRMQRData(rmqrSymbols[index], ecl, segments)
+	return newRMQR(index, ecl, data), nil
+}
+
+// encodeRMQRData encodes segments, and returns final sequence of codewords and remainder bits
+// reference: ISO/IEC 23941 : 2022 7.4
+func encodeRMQRData(symbol rmqrSymbol, ecl ErrorCorrectionLevel, segments []Segment) *bitset.BitSet {
+	bs := bitset.NewBitSet(symbol.dataCodeWords(ecl) * 8)
+
+	for _, s := range segments {
+		bs.SetInt(rmqrModeIndicators[s.Mode], rmqrModeIndicatorBits)
+		addCharacterCountIndicator(bs, symbol.characterCountIndicatorBits(s.Mode), characterCount(s.Mode, s.Data))
+		addSrcData(bs, s.Mode, s.Data)
+	}
+
+	// terminator is omitted or truncated if data bits are not enough
+	for i := 0; i < rmqrTerminatorBits && bs.Position() < bs.Length(); i++ {
+		bs.SetBool(false)
+	}
+	addPaddingBit(bs)
+
+	blocks := splitBlocks(symbol.ecBlocks(ecl), bs)
+	return interleaveBlocks(blocks, symbol.remainderBits)
+}
+
+// newRMQR returns rMQR of index of rmqrSymbols
+func newRMQR(index int, ecl ErrorCorrectionLevel, data *bitset.BitSet) *QRCode {
+	symbol := rmqrSymbols[index]
+
+	q := &QRCode{
+		version:   index + 1,
+		ecl:       ecl,
+		data:      data,
+		width:     symbol.width,
+		height:    symbol.height,
+		kind:      kindRMQR,
+		quietZone: rmqrQuietZoneSize,
+	}
+	q.initModules()
+	q.buildRMQR(index)
+
+	return q
+}
+
+// buildRMQR places function patterns and data
+// reference: ISO/IEC 23941 : 2022 6.3
+func (q *QRCode) buildRMQR(index int) {
+	q.add2dPattern(0, 0, finderPattern)
+
+	// separator is on the right side of the finder pattern, and below it if height is enough
+	for i := 0; i <= finderPatternSize; i++ {
+		if i < q.height {
+			q.add(finderPatternSize, i, false)
+		}
+		if q.height > finderPatternSize+1 {
+			q.add(i, finderPatternSize, false)
+		}
+	}
+
+	// finder sub pattern has the same shape as alignment pattern of QR code
+	q.add2dPattern(q.width-finderSubPatternSize, q.height-finderSubPatternSize, alignmentPattern)
+
+	q.addRMQRCornerPatterns()
+	q.addRMQRAlignmentPatterns()
+
+	// timing patterns are on the edges which are not occupied by other patterns
+	for x := 0; x < q.width; x++ {
+		for _, y := range []int{0, q.height - 1} {
+			if !q.isDirty(x, y) {
+				q.add(x, y, x%2 == 0)
+			}
+		}
+	}
+	for y := 0; y < q.height; y++ {
+		for _, x := range []int{0, q.width - 1} {
+			if !q.isDirty(x, y) {
+				q.add(x, y, y%2 == 0)
+			}
+		}
+	}
+
+	q.addRMQRFormatInfo(index)
+	q.addData()
+}
+
+// addRMQRCornerPatterns adds corner finder sub patterns on the top right and bottom left
+func (q *QRCode) addRMQRCornerPatterns() {
+	// top right
+	q.add(q.width-1, 0, true)
+	q.add(q.width-2, 0, true)
+	q.add(q.width-1, 1, true)
+	q.add(q.width-2, 1, false)
+
+	// bottom left is a part of finder pattern in R7
+	if q.height <= finderPatternSize {
+		return
+	}
+	q.add(0, q.height-1, true)
+	q.add(1, q.height-1, true)
+	q.add(2, q.height-1, true)
+
+	// the module above is separator in R9
+	if q.height > finderPatternSize+2 {
+		q.add(0, q.height-2, true)
+		q.add(1, q.height-2, false)
+	}
+}
+
+// addRMQRAlignmentPatterns adds alignment patterns on the top and bottom edges, and vertical timing patterns between them
+func (q *QRCode) addRMQRAlignmentPatterns() {
+	for _, x := range rmqrAlignmentPatternColumns[q.width] {
+		for _, top := range []int{0, q.height - 3} {
+			for dy := 0; dy < 3; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					// center is light
+					q.add(x+dx, top+dy, dx != 0 || dy != 1)
+				}
+			}
+		}
+
+		for y := 3; y < q.height-3; y++ {
+			q.add(x, y, y%2 == 0)
+		}
+	}
+}
+
+// rmqrFormatInfo returns format information on the finder pattern side and the finder sub pattern side
+// format information has 1 bit error correction level, 5 bits version indicator and 12 bits BCH code
+// reference: ISO/IEC 23941 : 2022 7.9.1
+func rmqrFormatInfo(ecl ErrorCorrectionLevel, index int) (left uint32, right uint32) {
+	data := uint32(index)
+	if ecl == ECL_Highest {
+		data |= 1 << 5
+	}
+
+	v := data << 12
+	for i := rmqrFormatInfoLength - 1; i >= 12; i-- {
+		if v>>i&1 == 1 {
+			v ^= rmqrFormatGenerator << (i - 12)
+		}
+	}
+	code := data<<12 | v
+
+	return code ^ rmqrLeftFormatMask, code ^ rmqrRightFormatMask
+}
+
+// addRMQRFormatInfo adds format information on the right of the finder pattern and on the left of the finder sub pattern
+func (q *QRCode) addRMQRFormatInfo(index int) {
+	left, right := rmqrFormatInfo(q.ecl, index)
+
+	for i := 0; i < 15; i++ {
+		q.add(finderPatternSize+1+i/5, 1+i%5, left>>i&1 == 1)
+		q.add(q.width-8+i/5, q.height-6+i%5, right>>i&1 == 1)
+	}
+	for i := 15; i < rmqrFormatInfoLength; i++ {
+		q.add(finderPatternSize+4, i-14, left>>i&1 == 1)
+		q.add(q.width-20+i, q.height-6, right>>i&1 == 1)
+	}
+}
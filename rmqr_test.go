@@ -0,0 +1,130 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ksrnnb/qrcode/bitset"
+)
+
+func TestRMQRSymbolBlocks(t *testing.T) {
+	for i, symbol := range rmqrSymbols {
+		for _, ecl := range []ErrorCorrectionLevel{ECL_Medium, ECL_Highest} {
+			total := 0
+			data := 0
+			ecwords := -1
+			for _, b := range symbol.ecBlocks(ecl) {
+				total += b.count * b.totalCodeWords
+				data += b.count * b.dataCodeWords
+
+				// all blocks have the same number of error correction codewords
+				if ecwords >= 0 && b.totalCodeWords-b.dataCodeWords != ecwords {
+					t.Errorf("R%dx%d: error correction codewords are different between blocks\n", symbol.height, symbol.width)
+				}
+				ecwords = b.totalCodeWords - b.dataCodeWords
+			}
+
+			if total != symbol.totalCodeWords {
+				t.Errorf("index %d: expected %d, got %d\n", i, symbol.totalCodeWords, total)
+			}
+			if data != symbol.dataCodeWords(ecl) {
+				t.Errorf("index %d: expected %d, got %d\n", i, symbol.dataCodeWords(ecl), data)
+			}
+		}
+	}
+}
+
+func TestRMQRDataModules(t *testing.T) {
+	for i, symbol := range rmqrSymbols {
+		// data modules must be filled with codewords and remainder bits exactly
+		data := bitset.NewBitSet(symbol.totalCodeWords*8 + symbol.remainderBits)
+		q := newRMQR(i, ECL_Medium, data)
+
+		for y := 0; y < q.height; y++ {
+			for x := 0; x < q.width; x++ {
+				if !q.isDirty(x, y) {
+					t.Errorf("R%dx%d: module (%d, %d) is empty\n", symbol.height, symbol.width, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestRMQRFormatInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		ecl       ErrorCorrectionLevel
+		index     int
+		wantLeft  uint32
+		wantRight uint32
+	}{
+		{
+			name:      "R7x43-M",
+			ecl:       ECL_Medium,
+			index:     0,
+			wantLeft:  0x1FAB2,
+			wantRight: 0x20A7B,
+		},
+		{
+			// 1 00001 => 100001 011011110000
+			name:      "R7x59-H",
+			ecl:       ECL_Highest,
+			index:     1,
+			wantLeft:  0b100001_011011110000 ^ 0x1FAB2,
+			wantRight: 0b100001_011011110000 ^ 0x20A7B,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			left, right := rmqrFormatInfo(test.ecl, test.index)
+			if left != test.wantLeft {
+				t.Errorf("expected %018b, got %018b\n", test.wantLeft, left)
+			}
+			if right != test.wantRight {
+				t.Errorf("expected %018b, got %018b\n", test.wantRight, right)
+			}
+		})
+	}
+}
+
+func TestNewRMQR(t *testing.T) {
+	tests := []struct {
+		name       string
+		ecl        ErrorCorrectionLevel
+		src        string
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "smallest symbol", ecl: ECL_Medium, src: "123", wantWidth: 27, wantHeight: 11},
+		{name: "R13x27 is smaller than R7x59", ecl: ECL_Medium, src: "123456789012345678", wantWidth: 27, wantHeight: 13},
+		{name: "largest symbol", ecl: ECL_Highest, src: strings.Repeat("a", 70), wantWidth: 139, wantHeight: 17},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := NewRMQR(test.ecl, test.src)
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if q.width != test.wantWidth || q.height != test.wantHeight {
+				t.Errorf("expected R%dx%d, got R%dx%d\n", test.wantHeight, test.wantWidth, q.height, q.width)
+			}
+
+			img := q.Image(0)
+			if img.Bounds().Dx() != q.width+2*rmqrQuietZoneSize || img.Bounds().Dy() != q.height+2*rmqrQuietZoneSize {
+				t.Errorf("unexpected image size %v\n", img.Bounds())
+			}
+		})
+	}
+}
+
+func TestNewRMQRError(t *testing.T) {
+	if _, err := NewRMQR(ECL_Low, "1"); err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+	if _, err := NewRMQR(ECL_Highest, strings.Repeat("a", 80)); err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+}
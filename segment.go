@@ -0,0 +1,303 @@
+package qrcode
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// Segment is a part of content which is encoded in the same mode
+// Data of Kanji mode is UTF-8 string, and it is converted to Shift_JIS when it is encoded
+type Segment struct {
+	Mode ModeIndicator
+	Data string
+}
+
+// segmentModes shows modes which can be used for segments
+// hanzi mode is used only if headerBitsFunc allows it, because scanners may not support it
+var segmentModes = []ModeIndicator{Numeric, AlphaNumeric, EightBits, Kanji, Hanzi}
+
+// bitLength returns number of bits of the segment including mode indicator and character count indicator
+func (s Segment) bitLength(version int) int {
+	return modeCharCount + characterCountIndicatorBits(version, s.Mode) + s.dataBitLength()
+}
+
+// dataBitLength returns number of bits of the segment excluding mode indicator and character count indicator
+func (s Segment) dataBitLength() int {
+	switch s.Mode {
+	case ECI:
+		assignment, _ := eciAssignment(s.Data)
+		return eciDesignatorBits(assignment)
+	case StructuredAppend:
+		return structuredAppendHeaderBits
+	case FNC1First:
+		return 0
+	case FNC1Second:
+		return applicationIndicatorBits
+	}
+
+	n := characterCount(s.Mode, s.Data)
+	switch s.Mode {
+	case Numeric:
+		// 3 digits => 10 bits, 2 digits => 7 bits, 1 digit => 4 bits
+		bits := n / 3 * 10
+		if n%3 == 2 {
+			bits += 7
+		} else if n%3 == 1 {
+			bits += 4
+		}
+		return bits
+	case AlphaNumeric:
+		// 2 characters => 11 bits, 1 character => 6 bits
+		return n/2*11 + n%2*6
+	case EightBits:
+		return n * 8
+	case Kanji:
+		return n * 13
+	case Hanzi:
+		return hanziSubsetBits + n*13
+	}
+	return 0
+}
+
+// validate returns error if the segment has characters which cannot be encoded in its mode
+func (s Segment) validate() error {
+	switch s.Mode {
+	case ECI:
+		_, err := eciAssignment(s.Data)
+		return err
+	case StructuredAppend:
+		return validateStructuredAppendHeader(s.Data)
+	case FNC1First:
+		if s.Data != "" {
+			return fmt.Errorf("FNC1 in first position must not have data, but got %q", s.Data)
+		}
+		return nil
+	case FNC1Second:
+		_, err := applicationIndicator(s.Data)
+		return err
+	}
+
+	for _, c := range s.Data {
+		ok := true
+		switch s.Mode {
+		case Numeric:
+			ok = isNumeric(c)
+		case AlphaNumeric:
+			ok = isAlphaNumeric(c)
+		case Kanji:
+			ok = isKanji(c)
+		case Hanzi:
+			ok = isHanzi(c)
+		case EightBits:
+			// any byte sequence can be encoded
+		default:
+			return fmt.Errorf("mode %s is not supported for segment", s.Mode)
+		}
+
+		if !ok {
+			return fmt.Errorf("%q cannot be encoded in %s mode", c, s.Mode)
+		}
+	}
+	return nil
+}
+
+// segmentsBitLength returns total number of bits of segments
+func segmentsBitLength(segments []Segment, version int) int {
+	bits := 0
+	for _, s := range segments {
+		bits += s.bitLength(version)
+	}
+	return bits
+}
+
+// byteEncoder converts a character to byte sequence of a character set for 8 bits byte mode
+// c is UTF-8 byte sequence of a character, or a single byte if src is not valid UTF-8
+// it returns false if the character is not included in the character set
+type byteEncoder func(c string) ([]byte, bool)
+
+// encodeUTF8 returns UTF-8 byte sequence as it is
+func encodeUTF8(c string) ([]byte, bool) {
+	return []byte(c), true
+}
+
+// headerBitsFunc returns number of bits of mode indicator and character count indicator of the mode in a symbol
+// it returns false if the mode cannot be used in the symbol
+type headerBitsFunc func(mode ModeIndicator) (int, bool)
+
+// qrHeaderBits returns headerBitsFunc of QR code of the version
+// subset indicator of hanzi mode is included in header, and hanzi mode is used only if hanzi is true
+func qrHeaderBits(version int, hanzi bool) headerBitsFunc {
+	return func(mode ModeIndicator) (int, bool) {
+		if mode == Hanzi {
+			return modeCharCount + hanziSubsetBits + characterCountIndicatorBits(version, mode), hanzi
+		}
+		return modeCharCount + characterCountIndicatorBits(version, mode), true
+	}
+}
+
+// optimizeSymbolSegments splits src into segments so that total bit length is minimized in a symbol whose header bits are headerBits
+//
+// costs are calculated in 1/6 bit to handle the fractional bits of numeric (10/3 bits) and alpha numeric (11/2 bits) mode,
+// and the cost of a character in each mode is calculated by dynamic programming
+func optimizeSymbolSegments(src string, headerBits headerBitsFunc, encoder byteEncoder, fnc1 bool) []Segment {
+	if src == "" {
+		if _, ok := headerBits(EightBits); ok {
+			return []Segment{{Mode: EightBits, Data: ""}}
+		}
+		return []Segment{{Mode: Numeric, Data: ""}}
+	}
+
+	// headCosts are costs of mode indicator and character count indicator
+	// modes which cannot be used in the symbol have infinite costs
+	headCosts := make([]int, len(segmentModes))
+	for i, mode := range segmentModes {
+		bits, ok := headerBits(mode)
+		if !ok {
+			headCosts[i] = math.MaxInt
+			continue
+		}
+		headCosts[i] = bits * 6
+	}
+
+	// splits src into characters, and converts them for alpha numeric and 8 bits byte mode
+	var chars []string
+	var runes []rune
+	var alphaNumerics []string
+	var encoded [][]byte
+	for pos := 0; pos < len(src); {
+		r, size := utf8.DecodeRuneInString(src[pos:])
+		c := src[pos : pos+size]
+		b, ok := encoder(c)
+		if !ok {
+			b = nil
+		}
+
+		chars = append(chars, c)
+		runes = append(runes, r)
+		alphaNumerics = append(alphaNumerics, alphaNumericChars(r, fnc1))
+		encoded = append(encoded, b)
+		pos += size
+	}
+
+	// prevModes[i][m] is the mode of i-th character when the segment after i-th character is in segmentModes[m]
+	prevModes := make([][]int, len(runes))
+	costs := make([]int, len(segmentModes))
+	copy(costs, headCosts)
+
+	for i, c := range runes {
+		nextCosts := make([]int, len(segmentModes))
+		prevModes[i] = make([]int, len(segmentModes))
+
+		// extends current segment
+		for m, mode := range segmentModes {
+			nextCosts[m] = math.MaxInt
+			prevModes[i][m] = -1
+
+			cost, ok := characterCost(mode, c, alphaNumerics[i], encoded[i])
+			if !ok || costs[m] == math.MaxInt {
+				continue
+			}
+			nextCosts[m] = costs[m] + cost
+			prevModes[i][m] = m
+		}
+
+		// starts new segment after the character
+		extended := make([]int, len(segmentModes))
+		copy(extended, nextCosts)
+		for to := range segmentModes {
+			for from := range segmentModes {
+				if extended[from] == math.MaxInt || headCosts[to] == math.MaxInt {
+					continue
+				}
+				// segment is padded to whole bits
+				cost := (extended[from]+5)/6*6 + headCosts[to]
+				if cost < nextCosts[to] {
+					nextCosts[to] = cost
+					prevModes[i][to] = from
+				}
+			}
+		}
+
+		costs = nextCosts
+	}
+
+	// finds the mode of the last character which has minimum cost
+	current := 0
+	for m := range segmentModes {
+		if costs[m] < costs[current] {
+			current = m
+		}
+	}
+	if costs[current] == math.MaxInt {
+		// some characters cannot be encoded in any mode
+		return nil
+	}
+
+	// traces back the mode of each character
+	modes := make([]ModeIndicator, len(runes))
+	for i := len(runes) - 1; i >= 0; i-- {
+		current = prevModes[i][current]
+		modes[i] = segmentModes[current]
+	}
+
+	// concatenates characters which have the same mode
+	var segments []Segment
+	var data []byte
+	for i, c := range chars {
+		if i > 0 && modes[i] != modes[i-1] {
+			segments = append(segments, Segment{Mode: modes[i-1], Data: string(data)})
+			data = nil
+		}
+
+		switch modes[i] {
+		case EightBits:
+			data = append(data, encoded[i]...)
+		case AlphaNumeric:
+			data = append(data, alphaNumerics[i]...)
+		default:
+			data = append(data, c...)
+		}
+	}
+	segments = append(segments, Segment{Mode: modes[len(runes)-1], Data: string(data)})
+
+	return segments
+}
+
+// characterCost returns cost of c in 1/6 bit
+// alphaNumeric is characters of c in alpha numeric mode, and it is empty if c cannot be encoded in alpha numeric mode
+// encoded is byte sequence of c for 8 bits byte mode, and it is nil if c cannot be encoded in 8 bits byte mode
+// it returns false if c cannot be encoded in the mode
+func characterCost(mode ModeIndicator, c rune, alphaNumeric string, encoded []byte) (int, bool) {
+	switch mode {
+	case Numeric:
+		return 20, isNumeric(c)
+	case AlphaNumeric:
+		return len(alphaNumeric) * 33, alphaNumeric != ""
+	case Kanji:
+		return 78, isKanji(c)
+	case Hanzi:
+		return 78, isHanzi(c)
+	default:
+		return len(encoded) * 8 * 6, encoded != nil
+	}
+}
+
+// alphaNumericChars returns characters of c in alpha numeric mode, and returns empty string if c cannot be encoded
+// in FNC1 mode, "%" is used as GS (0x1D) and literal "%" is encoded as "%%"
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 7.4.8.1
+func alphaNumericChars(c rune, fnc1 bool) string {
+	if fnc1 {
+		switch c {
+		case groupSeparator:
+			return "%"
+		case '%':
+			return "%%"
+		}
+	}
+
+	if !isAlphaNumeric(c) {
+		return ""
+	}
+	return string(c)
+}
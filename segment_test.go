@@ -0,0 +1,254 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSegmentBitLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		segment Segment
+		want    int
+	}{
+		{
+			name:    "numeric with final 2 digits",
+			version: 1,
+			segment: Segment{Mode: Numeric, Data: "01234567"},
+			want:    4 + 10 + 27,
+		},
+		{
+			name:    "alpha numeric with odd characters",
+			version: 1,
+			segment: Segment{Mode: AlphaNumeric, Data: "AC-42"},
+			want:    4 + 9 + 28,
+		},
+		{
+			name:    "8 bits byte counts UTF-8 bytes",
+			version: 10,
+			segment: Segment{Mode: EightBits, Data: "café"},
+			want:    4 + 16 + 40,
+		},
+		{
+			name:    "kanji",
+			version: 27,
+			segment: Segment{Mode: Kanji, Data: "点茗"},
+			want:    4 + 12 + 26,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.segment.bitLength(test.version)
+			if result != test.want {
+				t.Errorf("expected %d, got %d\n", test.want, result)
+			}
+		})
+	}
+}
+
+func TestOptimizeSegments(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		version int
+		want    []Segment
+	}{
+		{
+			name:    "empty",
+			src:     "",
+			version: 1,
+			want:    []Segment{{Mode: EightBits, Data: ""}},
+		},
+		{
+			name:    "digits only",
+			src:     "0123456789",
+			version: 1,
+			want:    []Segment{{Mode: Numeric, Data: "0123456789"}},
+		},
+		{
+			name:    "upper case letters",
+			src:     "HTTPS://EXAMPLE.COM/0123",
+			version: 1,
+			want:    []Segment{{Mode: AlphaNumeric, Data: "HTTPS://EXAMPLE.COM/0123"}},
+		},
+		{
+			name:    "kanji only",
+			src:     "点茗",
+			version: 1,
+			want:    []Segment{{Mode: Kanji, Data: "点茗"}},
+		},
+		{
+			name:    "short digits are not separated from letters",
+			src:     "abc123def",
+			version: 1,
+			want:    []Segment{{Mode: EightBits, Data: "abc123def"}},
+		},
+		{
+			name:    "mixed content",
+			src:     "ORDER 12345678 / tokyo",
+			version: 1,
+			want: []Segment{
+				{Mode: AlphaNumeric, Data: "ORDER 12345678 / "},
+				{Mode: EightBits, Data: "tokyo"},
+			},
+		},
+		{
+			name:    "long digits are separated",
+			src:     "ORDER 123456789012 / tokyo",
+			version: 1,
+			want: []Segment{
+				{Mode: AlphaNumeric, Data: "ORDER "},
+				{Mode: Numeric, Data: "123456789012"},
+				{Mode: EightBits, Data: " / tokyo"},
+			},
+		},
+		{
+			name:    "kanji and ascii",
+			src:     "東京タワー333m",
+			version: 1,
+			want: []Segment{
+				{Mode: Kanji, Data: "東京タワー"},
+				{Mode: EightBits, Data: "333m"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := optimizeSegments(test.src, test.version, encodeUTF8, false)
+			if len(result) != len(test.want) {
+				t.Errorf("expected %v, got %v\n", test.want, result)
+				return
+			}
+			for i, want := range test.want {
+				if result[i] != want {
+					t.Errorf("expected %v, got %v at index %d\n", want, result[i], i)
+				}
+			}
+		})
+	}
+}
+
+func TestOptimizeSegmentsWithEncoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		encoder byteEncoder
+		want    []Segment
+	}{
+		{
+			name:    "invalid UTF-8 is kept as it is",
+			src:     "\xff\xfe",
+			encoder: encodeUTF8,
+			want:    []Segment{{Mode: EightBits, Data: "\xff\xfe"}},
+		},
+		{
+			name:    "converted by encoder",
+			src:     "añb",
+			encoder: iso8859Encoder(1),
+			want:    []Segment{{Mode: EightBits, Data: "a\xf1b"}},
+		},
+		{
+			name:    "character which cannot be encoded",
+			src:     "aŁb",
+			encoder: iso8859Encoder(1),
+			want:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := optimizeSegments(test.src, 1, test.encoder, false)
+			if len(result) != len(test.want) {
+				t.Errorf("expected %q, got %q\n", test.want, result)
+				return
+			}
+			for i, want := range test.want {
+				if result[i] != want {
+					t.Errorf("expected %q, got %q at index %d\n", want, result[i], i)
+				}
+			}
+		})
+	}
+}
+
+func TestOptimizeSegmentsIsNotLongerThanSingleMode(t *testing.T) {
+	srcs := []string{
+		"ORDER 12345678 / tokyo",
+		"0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+		strings.Repeat("123ab", 20),
+		"点茗 and 123456789012345",
+	}
+
+	for _, src := range srcs {
+		for _, version := range []int{1, 10, 27} {
+			optimized := segmentsBitLength(optimizeSegments(src, version, encodeUTF8, false), version)
+			single := Segment{Mode: EightBits, Data: src}.bitLength(version)
+			if optimized > single {
+				t.Errorf("%q in version %d: optimized segments have %d bits, but 8 bits byte mode has %d bits\n", src, version, optimized, single)
+			}
+		}
+	}
+}
+
+func TestBuildSegmentsHanzi(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+		want []Segment
+	}{
+		{
+			name: "simplified chinese in hanzi mode",
+			src:  "们们们",
+			opts: Options{Hanzi: true},
+			want: []Segment{{Mode: Hanzi, Data: "们们们"}},
+		},
+		{
+			name: "hanzi mode is not used without option",
+			src:  "们们们",
+			opts: Options{},
+			want: []Segment{{Mode: EightBits, Data: "们们们"}},
+		},
+		{
+			name: "kanji mode is preferred for characters in both character sets",
+			src:  "中国",
+			opts: Options{Hanzi: true},
+			want: []Segment{{Mode: Kanji, Data: "中国"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := buildSegments(test.src, 1, test.opts)
+			if len(result) != len(test.want) {
+				t.Errorf("expected %v, got %v\n", test.want, result)
+				return
+			}
+			for i, want := range test.want {
+				if result[i] != want {
+					t.Errorf("expected %v, got %v at index %d\n", want, result[i], i)
+				}
+			}
+		})
+	}
+}
+
+func TestSegmentValidateHanzi(t *testing.T) {
+	if err := (Segment{Mode: Hanzi, Data: "们"}).validate(); err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+	if err := (Segment{Mode: Hanzi, Data: "體"}).validate(); err == nil {
+		t.Errorf("error is expected, but got nil\n")
+	}
+}
+
+// optimizeSegments splits src into segments so that total bit length is minimized in the version
+// bit length depends on the version because character count indicator bits change at version 10 and 27
+// data of 8 bits byte segments is converted by encoder, and it returns nil if src has a character which cannot be encoded
+// if fnc1 is true, GS is encoded as "%" and "%" is escaped as "%%" in alpha numeric mode
+func optimizeSegments(src string, version int, encoder byteEncoder, fnc1 bool) []Segment {
+	return optimizeSymbolSegments(src, qrHeaderBits(version, false), encoder, fnc1)
+}
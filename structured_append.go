@@ -0,0 +1,169 @@
+package qrcode
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/ksrnnb/qrcode/charset"
+)
+
+const (
+	// max number of symbols which can be concatenated by structured append
+	maxStructuredAppendSymbols = 16
+
+	// symbol sequence indicator (8 bits) + parity data (8 bits)
+	structuredAppendHeaderBits = 16
+)
+
+// StructuredAppendSegment returns header segment of structured append
+// index is position of the symbol (0-15), total is number of symbols (1-16),
+// and parity is XOR of all bytes of the whole message which is split into symbols
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) 8
+func StructuredAppendSegment(index int, total int, parity byte) (Segment, error) {
+	if total < 1 || total > maxStructuredAppendSymbols {
+		return Segment{}, fmt.Errorf("number of symbols of structured append must be 1 to %d, but got %d", maxStructuredAppendSymbols, total)
+	}
+	if index < 0 || index >= total {
+		return Segment{}, fmt.Errorf("position of structured append must be 0 to %d, but got %d", total-1, index)
+	}
+
+	// symbol sequence indicator: 4 bits position + 4 bits (total number - 1)
+	indicator := byte(index<<4 | (total - 1))
+	return Segment{Mode: StructuredAppend, Data: string([]byte{indicator, parity})}, nil
+}
+
+// validateStructuredAppendHeader returns error if data is not valid structured append header
+func validateStructuredAppendHeader(data string) error {
+	if len(data) != 2 {
+		return fmt.Errorf("structured append header must be 2 bytes, but got %d bytes", len(data))
+	}
+
+	index := int(data[0] >> 4)
+	total := int(data[0]&0x0F) + 1
+	if index >= total {
+		return fmt.Errorf("position of structured append %d must be less than number of symbols %d", index, total)
+	}
+	return nil
+}
+
+// structuredAppendParity returns XOR of all bytes of data which are encoded in segments
+// characters of kanji and hanzi modes are double byte codes of Shift_JIS and GB 2312
+func structuredAppendParity(segments []Segment) byte {
+	var parity byte
+	for _, s := range segments {
+		var data []byte
+		switch s.Mode {
+		case Numeric, AlphaNumeric, EightBits:
+			data = []byte(s.Data)
+		case Kanji:
+			for _, c := range s.Data {
+				b, _ := charset.ShiftJISBytes(c)
+				data = append(data, b...)
+			}
+		case Hanzi:
+			for _, c := range s.Data {
+				code, _ := charset.GB2312(c)
+				data = append(data, byte(code>>8), byte(code))
+			}
+		}
+
+		for _, b := range data {
+			parity ^= b
+		}
+	}
+	return parity
+}
+
+// NewStructuredAppend splits content evenly into up to 16 QR codes which are concatenated by structured append
+// all symbols have the same version which is the smallest version not greater than maxSymbolVersion
+// if content fits in one symbol, it is a plain QR code without structured append header
+func NewStructuredAppend(ecl ErrorCorrectionLevel, content string, maxSymbolVersion int) ([]*QRCode, error) {
+	if maxSymbolVersion < minVersion || maxSymbolVersion > maxVersion {
+		return nil, fmt.Errorf("max version must be %d to %d, but got %d", minVersion, maxVersion, maxSymbolVersion)
+	}
+
+	numChars := utf8.RuneCountInString(content)
+
+	for total := 1; total <= maxStructuredAppendSymbols; total++ {
+		// each symbol must have at least one character
+		if total > 1 && total > numChars {
+			break
+		}
+
+		parts := splitEvenly(content, total)
+		infos, ok := findStructuredAppendInfos(ecl, parts, maxSymbolVersion)
+		if !ok {
+			continue
+		}
+
+		codes := make([]*QRCode, len(infos))
+		for i, info := range infos {
+			q, err := newQRCodeWithBestMask(info)
+			if err != nil {
+				return nil, err
+			}
+			codes[i] = q
+		}
+		return codes, nil
+	}
+
+	return nil, fmt.Errorf("content is too long to be split into %d symbols of version %d", maxStructuredAppendSymbols, maxSymbolVersion)
+}
+
+// findStructuredAppendInfos returns qrInfo of each part in the smallest version up to versionLimit which can contain all parts
+func findStructuredAppendInfos(ecl ErrorCorrectionLevel, parts []string, versionLimit int) ([]qrInfo, bool) {
+	opts := Options{ECL: ecl}
+
+	for version := minVersion; version <= versionLimit; version++ {
+		// parity is calculated from encoded data of all parts, which depends on segments of the version
+		partSegments := make([][]Segment, len(parts))
+		var all []Segment
+		for i, part := range parts {
+			partSegments[i] = buildSegments(part, version, opts)
+			all = append(all, partSegments[i]...)
+		}
+		parity := structuredAppendParity(all)
+
+		infos := make([]qrInfo, len(parts))
+		fits := true
+		for i, segments := range partSegments {
+			// header of a single symbol is not needed
+			if len(parts) > 1 {
+				header, err := StructuredAppendSegment(i, len(parts), parity)
+				if err != nil {
+					return nil, false
+				}
+				segments = append([]Segment{header}, segments...)
+			}
+			infos[i] = newQRInfo(version, ecl, segments)
+			if !infos[i].fits() {
+				fits = false
+				break
+			}
+		}
+
+		if fits {
+			return infos, true
+		}
+	}
+	return nil, false
+}
+
+// splitEvenly splits src into n parts which have almost the same number of characters
+func splitEvenly(src string, n int) []string {
+	// start positions of characters
+	var starts []int
+	for pos := 0; pos < len(src); {
+		_, size := utf8.DecodeRuneInString(src[pos:])
+		starts = append(starts, pos)
+		pos += size
+	}
+	starts = append(starts, len(src))
+
+	numChars := len(starts) - 1
+	parts := make([]string, n)
+	for i := 0; i < n; i++ {
+		parts[i] = src[starts[i*numChars/n]:starts[(i+1)*numChars/n]]
+	}
+	return parts
+}
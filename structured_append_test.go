@@ -0,0 +1,222 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStructuredAppendSegment(t *testing.T) {
+	s, err := StructuredAppendSegment(2, 4, 0x5A)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+		return
+	}
+	if s.Mode != StructuredAppend {
+		t.Errorf("expected %s, got %s\n", StructuredAppend, s.Mode)
+	}
+	if s.Data != "\x23\x5a" {
+		t.Errorf("expected %q, got %q\n", "\x23\x5a", s.Data)
+	}
+	if err := s.validate(); err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+
+	// 16 symbols is the max number
+	if _, err := StructuredAppendSegment(15, 16, 0); err != nil {
+		t.Errorf("error: %v\n", err)
+	}
+}
+
+func TestStructuredAppendSegmentOutOfRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		index int
+		total int
+	}{
+		{name: "position is number of symbols", index: 4, total: 4},
+		{name: "negative position", index: -1, total: 4},
+		{name: "position is over 15", index: 16, total: 16},
+		{name: "17 symbols", index: 0, total: 17},
+		{name: "no symbol", index: 0, total: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := StructuredAppendSegment(test.index, test.total, 0); err == nil {
+				t.Errorf("expected error, got nil\n")
+			}
+		})
+	}
+}
+
+func TestStructuredAppendParity(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []Segment
+		want     byte
+	}{
+		{
+			// 'A' ^ 'B' ^ 'C' = 0x41 ^ 0x42 ^ 0x43 = 0x40
+			name:     "alpha numeric",
+			segments: []Segment{{Mode: AlphaNumeric, Data: "ABC"}},
+			want:     0x40,
+		},
+		{
+			// 点 (0x935F) ^ 茗 (0xE4AA) = 0x93 ^ 0x5F ^ 0xE4 ^ 0xAA = 0x82
+			name:     "kanji is Shift_JIS",
+			segments: []Segment{{Mode: Kanji, Data: "点茗"}},
+			want:     0x82,
+		},
+		{
+			// ECI designator is not data
+			name:     "ECI is ignored",
+			segments: []Segment{{Mode: ECI, Data: "26"}, {Mode: EightBits, Data: "AB"}},
+			want:     0x03,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if p := structuredAppendParity(test.segments); p != test.want {
+				t.Errorf("expected %#x, got %#x\n", test.want, p)
+			}
+		})
+	}
+}
+
+func TestSplitEvenly(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		n    int
+		want []string
+	}{
+		{
+			name: "divisible",
+			src:  "abcdef",
+			n:    3,
+			want: []string{"ab", "cd", "ef"},
+		},
+		{
+			name: "not divisible",
+			src:  "abcdefg",
+			n:    3,
+			want: []string{"ab", "cd", "efg"},
+		},
+		{
+			name: "multi bytes characters are not split",
+			src:  "点茗点茗",
+			n:    2,
+			want: []string{"点茗", "点茗"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := splitEvenly(test.src, test.n)
+			if len(result) != len(test.want) {
+				t.Errorf("expected %q, got %q\n", test.want, result)
+				return
+			}
+			for i, want := range test.want {
+				if result[i] != want {
+					t.Errorf("expected %q, got %q at index %d\n", want, result[i], i)
+				}
+			}
+		})
+	}
+}
+
+func TestNewStructuredAppend(t *testing.T) {
+	// version 1-H has 72 data bits: 20 bits header + 12 bits byte mode header + 5 bytes
+	content := strings.Repeat("a", 30)
+	codes, err := NewStructuredAppend(ECL_Highest, content, 1)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+		return
+	}
+	if len(codes) != 6 {
+		t.Errorf("expected %d, got %d\n", 6, len(codes))
+		return
+	}
+
+	parity := structuredAppendParity([]Segment{{Mode: EightBits, Data: content}})
+	for i, q := range codes {
+		if q.version != 1 {
+			t.Errorf("expected %d, got %d\n", 1, q.version)
+		}
+
+		// 0011 iiii 0101 pppppppp
+		header := 0b0011<<16 | i<<12 | 5<<8 | int(parity)
+		for j := 0; j < 20; j++ {
+			want := (header>>(19-j))&1 == 1
+			if q.data.GetValue(j) != want {
+				t.Errorf("expected %v, got %v at pos %d of symbol %d\n", want, q.data.GetValue(j), j, i)
+				break
+			}
+		}
+	}
+}
+
+func TestNewStructuredAppendKanji(t *testing.T) {
+	// version 1-H has 72 data bits: 20 bits header + 12 bits kanji mode header + 3 characters
+	content := "点茗点茗点"
+	codes, err := NewStructuredAppend(ECL_Highest, content, 1)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+		return
+	}
+	if len(codes) != 2 {
+		t.Errorf("expected %d, got %d\n", 2, len(codes))
+		return
+	}
+
+	// parity is calculated from Shift_JIS bytes, 点 (0x935F) appears odd times and 茗 (0xE4AA) even times
+	want := 0x93 ^ 0x5F
+	for i, q := range codes {
+		got := 0
+		for j := 12; j < 20; j++ {
+			got <<= 1
+			if q.data.GetValue(j) {
+				got |= 1
+			}
+		}
+		if got != want {
+			t.Errorf("expected %#x, got %#x in symbol %d\n", want, got, i)
+		}
+	}
+}
+
+func TestNewStructuredAppendSingleSymbol(t *testing.T) {
+	content := "hello"
+	codes, err := NewStructuredAppend(ECL_Highest, content, 1)
+	if err != nil {
+		t.Errorf("error: %v\n", err)
+		return
+	}
+	if len(codes) != 1 {
+		t.Errorf("expected %d, got %d\n", 1, len(codes))
+		return
+	}
+
+	// the symbol starts with 8 bits byte mode instead of structured append header
+	q := codes[0]
+	for j := 0; j < modeCharCount; j++ {
+		want := (int(EightBits)>>(modeCharCount-1-j))&1 == 1
+		if q.data.GetValue(j) != want {
+			t.Errorf("expected %v, got %v at pos %d\n", want, q.data.GetValue(j), j)
+		}
+	}
+}
+
+func TestNewStructuredAppendTooLong(t *testing.T) {
+	_, err := NewStructuredAppend(ECL_Highest, strings.Repeat("a", 81), 1)
+	if err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+
+	_, err = NewStructuredAppend(ECL_Highest, "a", 41)
+	if err == nil {
+		t.Errorf("expected error, got nil\n")
+	}
+}
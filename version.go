@@ -0,0 +1,271 @@
+package qrcode
+
+const (
+	minVersion = 1
+	maxVersion = 40
+)
+
+// ecBlock is a group of error correction blocks which have the same structure
+type ecBlock struct {
+	// number of error correction blocks
+	count int
+
+	// c: total number of codewords per block (data codewords + error correction codewords)
+	totalCodeWords int
+
+	// k: number of data codewords per block
+	dataCodeWords int
+}
+
+// ecBlocks shows error correction blocks for each error correction level and version
+// index of slice is version - 1
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 9
+var ecBlocks = map[ErrorCorrectionLevel][][]ecBlock{
+	ECL_Low: {
+		{{1, 26, 19}},                   // 1
+		{{1, 44, 34}},                   // 2
+		{{1, 70, 55}},                   // 3
+		{{1, 100, 80}},                  // 4
+		{{1, 134, 108}},                 // 5
+		{{2, 86, 68}},                   // 6
+		{{2, 98, 78}},                   // 7
+		{{2, 121, 97}},                  // 8
+		{{2, 146, 116}},                 // 9
+		{{2, 86, 68}, {2, 87, 69}},      // 10
+		{{4, 101, 81}},                  // 11
+		{{2, 116, 92}, {2, 117, 93}},    // 12
+		{{4, 133, 107}},                 // 13
+		{{3, 145, 115}, {1, 146, 116}},  // 14
+		{{5, 109, 87}, {1, 110, 88}},    // 15
+		{{5, 122, 98}, {1, 123, 99}},    // 16
+		{{1, 135, 107}, {5, 136, 108}},  // 17
+		{{5, 150, 120}, {1, 151, 121}},  // 18
+		{{3, 141, 113}, {4, 142, 114}},  // 19
+		{{3, 135, 107}, {5, 136, 108}},  // 20
+		{{4, 144, 116}, {4, 145, 117}},  // 21
+		{{2, 139, 111}, {7, 140, 112}},  // 22
+		{{4, 151, 121}, {5, 152, 122}},  // 23
+		{{6, 147, 117}, {4, 148, 118}},  // 24
+		{{8, 132, 106}, {4, 133, 107}},  // 25
+		{{10, 142, 114}, {2, 143, 115}}, // 26
+		{{8, 152, 122}, {4, 153, 123}},  // 27
+		{{3, 147, 117}, {10, 148, 118}}, // 28
+		{{7, 146, 116}, {7, 147, 117}},  // 29
+		{{5, 145, 115}, {10, 146, 116}}, // 30
+		{{13, 145, 115}, {3, 146, 116}}, // 31
+		{{17, 145, 115}},                // 32
+		{{17, 145, 115}, {1, 146, 116}}, // 33
+		{{13, 145, 115}, {6, 146, 116}}, // 34
+		{{12, 151, 121}, {7, 152, 122}}, // 35
+		{{6, 151, 121}, {14, 152, 122}}, // 36
+		{{17, 152, 122}, {4, 153, 123}}, // 37
+		{{4, 152, 122}, {18, 153, 123}}, // 38
+		{{20, 147, 117}, {4, 148, 118}}, // 39
+		{{19, 148, 118}, {6, 149, 119}}, // 40
+	},
+	ECL_Medium: {
+		{{1, 26, 16}},                // 1
+		{{1, 44, 28}},                // 2
+		{{1, 70, 44}},                // 3
+		{{2, 50, 32}},                // 4
+		{{2, 67, 43}},                // 5
+		{{4, 43, 27}},                // 6
+		{{4, 49, 31}},                // 7
+		{{2, 60, 38}, {2, 61, 39}},   // 8
+		{{3, 58, 36}, {2, 59, 37}},   // 9
+		{{4, 69, 43}, {1, 70, 44}},   // 10
+		{{1, 80, 50}, {4, 81, 51}},   // 11
+		{{6, 58, 36}, {2, 59, 37}},   // 12
+		{{8, 59, 37}, {1, 60, 38}},   // 13
+		{{4, 64, 40}, {5, 65, 41}},   // 14
+		{{5, 65, 41}, {5, 66, 42}},   // 15
+		{{7, 73, 45}, {3, 74, 46}},   // 16
+		{{10, 74, 46}, {1, 75, 47}},  // 17
+		{{9, 69, 43}, {4, 70, 44}},   // 18
+		{{3, 70, 44}, {11, 71, 45}},  // 19
+		{{3, 67, 41}, {13, 68, 42}},  // 20
+		{{17, 68, 42}},               // 21
+		{{17, 74, 46}},               // 22
+		{{4, 75, 47}, {14, 76, 48}},  // 23
+		{{6, 73, 45}, {14, 74, 46}},  // 24
+		{{8, 75, 47}, {13, 76, 48}},  // 25
+		{{19, 74, 46}, {4, 75, 47}},  // 26
+		{{22, 73, 45}, {3, 74, 46}},  // 27
+		{{3, 73, 45}, {23, 74, 46}},  // 28
+		{{21, 73, 45}, {7, 74, 46}},  // 29
+		{{19, 75, 47}, {10, 76, 48}}, // 30
+		{{2, 74, 46}, {29, 75, 47}},  // 31
+		{{10, 74, 46}, {23, 75, 47}}, // 32
+		{{14, 74, 46}, {21, 75, 47}}, // 33
+		{{14, 74, 46}, {23, 75, 47}}, // 34
+		{{12, 75, 47}, {26, 76, 48}}, // 35
+		{{6, 75, 47}, {34, 76, 48}},  // 36
+		{{29, 74, 46}, {14, 75, 47}}, // 37
+		{{13, 74, 46}, {32, 75, 47}}, // 38
+		{{40, 75, 47}, {7, 76, 48}},  // 39
+		{{18, 75, 47}, {31, 76, 48}}, // 40
+	},
+	ECL_High: {
+		{{1, 26, 13}},                // 1
+		{{1, 44, 22}},                // 2
+		{{2, 35, 17}},                // 3
+		{{2, 50, 24}},                // 4
+		{{2, 33, 15}, {2, 34, 16}},   // 5
+		{{4, 43, 19}},                // 6
+		{{2, 32, 14}, {4, 33, 15}},   // 7
+		{{4, 40, 18}, {2, 41, 19}},   // 8
+		{{4, 36, 16}, {4, 37, 17}},   // 9
+		{{6, 43, 19}, {2, 44, 20}},   // 10
+		{{4, 50, 22}, {4, 51, 23}},   // 11
+		{{4, 46, 20}, {6, 47, 21}},   // 12
+		{{8, 44, 20}, {4, 45, 21}},   // 13
+		{{11, 36, 16}, {5, 37, 17}},  // 14
+		{{5, 54, 24}, {7, 55, 25}},   // 15
+		{{15, 43, 19}, {2, 44, 20}},  // 16
+		{{1, 50, 22}, {15, 51, 23}},  // 17
+		{{17, 50, 22}, {1, 51, 23}},  // 18
+		{{17, 47, 21}, {4, 48, 22}},  // 19
+		{{15, 54, 24}, {5, 55, 25}},  // 20
+		{{17, 50, 22}, {6, 51, 23}},  // 21
+		{{7, 54, 24}, {16, 55, 25}},  // 22
+		{{11, 54, 24}, {14, 55, 25}}, // 23
+		{{11, 54, 24}, {16, 55, 25}}, // 24
+		{{7, 54, 24}, {22, 55, 25}},  // 25
+		{{28, 50, 22}, {6, 51, 23}},  // 26
+		{{8, 53, 23}, {26, 54, 24}},  // 27
+		{{4, 54, 24}, {31, 55, 25}},  // 28
+		{{1, 53, 23}, {37, 54, 24}},  // 29
+		{{15, 54, 24}, {25, 55, 25}}, // 30
+		{{42, 54, 24}, {1, 55, 25}},  // 31
+		{{10, 54, 24}, {35, 55, 25}}, // 32
+		{{29, 54, 24}, {19, 55, 25}}, // 33
+		{{44, 54, 24}, {7, 55, 25}},  // 34
+		{{39, 54, 24}, {14, 55, 25}}, // 35
+		{{46, 54, 24}, {10, 55, 25}}, // 36
+		{{49, 54, 24}, {10, 55, 25}}, // 37
+		{{48, 54, 24}, {14, 55, 25}}, // 38
+		{{43, 54, 24}, {22, 55, 25}}, // 39
+		{{34, 54, 24}, {34, 55, 25}}, // 40
+	},
+	ECL_Highest: {
+		{{1, 26, 9}},                 // 1
+		{{1, 44, 16}},                // 2
+		{{2, 35, 13}},                // 3
+		{{4, 25, 9}},                 // 4
+		{{2, 33, 11}, {2, 34, 12}},   // 5
+		{{4, 43, 15}},                // 6
+		{{4, 39, 13}, {1, 40, 14}},   // 7
+		{{4, 40, 14}, {2, 41, 15}},   // 8
+		{{4, 36, 12}, {4, 37, 13}},   // 9
+		{{6, 43, 15}, {2, 44, 16}},   // 10
+		{{3, 36, 12}, {8, 37, 13}},   // 11
+		{{7, 42, 14}, {4, 43, 15}},   // 12
+		{{12, 33, 11}, {4, 34, 12}},  // 13
+		{{11, 36, 12}, {5, 37, 13}},  // 14
+		{{11, 36, 12}, {7, 37, 13}},  // 15
+		{{3, 45, 15}, {13, 46, 16}},  // 16
+		{{2, 42, 14}, {17, 43, 15}},  // 17
+		{{2, 42, 14}, {19, 43, 15}},  // 18
+		{{9, 39, 13}, {16, 40, 14}},  // 19
+		{{15, 43, 15}, {10, 44, 16}}, // 20
+		{{19, 46, 16}, {6, 47, 17}},  // 21
+		{{34, 37, 13}},               // 22
+		{{16, 45, 15}, {14, 46, 16}}, // 23
+		{{30, 46, 16}, {2, 47, 17}},  // 24
+		{{22, 45, 15}, {13, 46, 16}}, // 25
+		{{33, 46, 16}, {4, 47, 17}},  // 26
+		{{12, 45, 15}, {28, 46, 16}}, // 27
+		{{11, 45, 15}, {31, 46, 16}}, // 28
+		{{19, 45, 15}, {26, 46, 16}}, // 29
+		{{23, 45, 15}, {25, 46, 16}}, // 30
+		{{23, 45, 15}, {28, 46, 16}}, // 31
+		{{19, 45, 15}, {35, 46, 16}}, // 32
+		{{11, 45, 15}, {46, 46, 16}}, // 33
+		{{59, 46, 16}, {1, 47, 17}},  // 34
+		{{22, 45, 15}, {41, 46, 16}}, // 35
+		{{2, 45, 15}, {64, 46, 16}},  // 36
+		{{24, 45, 15}, {46, 46, 16}}, // 37
+		{{42, 45, 15}, {32, 46, 16}}, // 38
+		{{10, 45, 15}, {67, 46, 16}}, // 39
+		{{20, 45, 15}, {61, 46, 16}}, // 40
+	},
+}
+
+// remainderBits shows number of remainder bits which are added after the final codeword
+// index of slice is version - 1
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 1
+var remainderBits = []int{
+	0, 7, 7, 7, 7, 7, 0, 0, 0, 0,
+	0, 0, 0, 3, 3, 3, 3, 3, 3, 3,
+	4, 4, 4, 4, 4, 4, 4, 3, 3, 3,
+	3, 3, 3, 3, 0, 0, 0, 0, 0, 0,
+}
+
+// symbolSize returns number of modules per side
+func symbolSize(version int) int {
+	return 17 + 4*version
+}
+
+// totalCodeWords returns total number of codewords (data codewords + error correction codewords)
+func totalCodeWords(version int, ecl ErrorCorrectionLevel) int {
+	total := 0
+	for _, b := range ecBlocks[ecl][version-1] {
+		total += b.count * b.totalCodeWords
+	}
+	return total
+}
+
+// dataCodeWords returns number of data codewords
+func dataCodeWords(version int, ecl ErrorCorrectionLevel) int {
+	total := 0
+	for _, b := range ecBlocks[ecl][version-1] {
+		total += b.count * b.dataCodeWords
+	}
+	return total
+}
+
+// alignmentPatternCenters shows row/column coordinates of center modules of alignment patterns
+// index of slice is version - 1
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Annex E Table E.1
+var alignmentPatternCenters = [][]int{
+	{},
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+	{6, 30, 54},
+	{6, 32, 58},
+	{6, 34, 62},
+	{6, 26, 46, 66},
+	{6, 26, 48, 70},
+	{6, 26, 50, 74},
+	{6, 30, 54, 78},
+	{6, 30, 56, 82},
+	{6, 30, 58, 86},
+	{6, 34, 62, 90},
+	{6, 28, 50, 72, 94},
+	{6, 26, 50, 74, 98},
+	{6, 30, 54, 78, 102},
+	{6, 28, 54, 80, 106},
+	{6, 32, 58, 84, 110},
+	{6, 30, 58, 86, 114},
+	{6, 34, 62, 90, 118},
+	{6, 26, 50, 74, 98, 122},
+	{6, 30, 54, 78, 102, 126},
+	{6, 26, 52, 78, 104, 130},
+	{6, 30, 56, 82, 108, 134},
+	{6, 34, 60, 86, 112, 138},
+	{6, 30, 58, 86, 114, 142},
+	{6, 34, 62, 90, 118, 146},
+	{6, 30, 54, 78, 102, 126, 150},
+	{6, 24, 50, 76, 102, 128, 154},
+	{6, 28, 54, 80, 106, 132, 158},
+	{6, 32, 58, 84, 110, 136, 162},
+	{6, 26, 54, 82, 110, 138, 166},
+	{6, 30, 58, 86, 114, 142, 170},
+}
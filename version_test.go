@@ -0,0 +1,198 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTotalCodeWords(t *testing.T) {
+	// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 1
+	wants := []int{
+		26, 44, 70, 100, 134, 172, 196, 242, 292, 346,
+		404, 466, 532, 581, 655, 733, 815, 901, 991, 1085,
+		1156, 1258, 1364, 1474, 1588, 1706, 1828, 1921, 2051, 2185,
+		2323, 2465, 2611, 2761, 2876, 3034, 3196, 3362, 3532, 3706,
+	}
+
+	for _, ecl := range []ErrorCorrectionLevel{ECL_Low, ECL_Medium, ECL_High, ECL_Highest} {
+		for i, want := range wants {
+			version := i + 1
+			if result := totalCodeWords(version, ecl); result != want {
+				t.Errorf("version %d, ecl %d: expected %d, got %d\n", version, ecl, want, result)
+			}
+		}
+	}
+}
+
+func TestCharacterCapacity(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		ecl     ErrorCorrectionLevel
+		mode    ModeIndicator
+		want    int
+	}{
+		{
+			name:    "1-L numeric",
+			version: 1,
+			ecl:     ECL_Low,
+			mode:    Numeric,
+			want:    41,
+		},
+		{
+			name:    "1-H alpha numeric",
+			version: 1,
+			ecl:     ECL_Highest,
+			mode:    AlphaNumeric,
+			want:    10,
+		},
+		{
+			name:    "1-M 8 bits byte",
+			version: 1,
+			ecl:     ECL_Medium,
+			mode:    EightBits,
+			want:    14,
+		},
+		{
+			name:    "10-Q kanji",
+			version: 10,
+			ecl:     ECL_High,
+			mode:    Kanji,
+			want:    93,
+		},
+		{
+			name:    "27-M alpha numeric",
+			version: 27,
+			ecl:     ECL_Medium,
+			mode:    AlphaNumeric,
+			want:    1637,
+		},
+		{
+			name:    "40-L numeric",
+			version: 40,
+			ecl:     ECL_Low,
+			mode:    Numeric,
+			want:    7089,
+		},
+		{
+			name:    "40-L 8 bits byte",
+			version: 40,
+			ecl:     ECL_Low,
+			mode:    EightBits,
+			want:    2953,
+		},
+		{
+			name:    "40-H kanji",
+			version: 40,
+			ecl:     ECL_Highest,
+			mode:    Kanji,
+			want:    784,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := characterCapacity(test.version, test.ecl, test.mode)
+			if result != test.want {
+				t.Errorf("expected %d, got %d\n", test.want, result)
+			}
+		})
+	}
+}
+
+func TestFindQRInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		ecl         ErrorCorrectionLevel
+		src         string
+		wantVersion int
+		wantErr     bool
+	}{
+		{
+			name:        "fits in version 1",
+			ecl:         ECL_Low,
+			src:         strings.Repeat("a", 17),
+			wantVersion: 1,
+		},
+		{
+			name:        "needs version 2",
+			ecl:         ECL_Low,
+			src:         strings.Repeat("a", 18),
+			wantVersion: 2,
+		},
+		{
+			name:        "multi-byte characters are counted in bytes",
+			ecl:         ECL_Low,
+			src:         strings.Repeat("é", 9),
+			wantVersion: 2,
+		},
+		{
+			name:        "fits in version 40",
+			ecl:         ECL_Highest,
+			src:         strings.Repeat("a", 1273),
+			wantVersion: 40,
+		},
+		{
+			name:    "too long",
+			ecl:     ECL_Highest,
+			src:     strings.Repeat("a", 1274),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info, err := findQRInfo(test.src, Options{ECL: test.ecl})
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("error is expected, but got nil\n")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("error: %v\n", err)
+				return
+			}
+			if info.version != test.wantVersion {
+				t.Errorf("expected version %d, got %d\n", test.wantVersion, info.version)
+			}
+		})
+	}
+}
+
+// characterCapacity returns max number of characters which can be encoded in the mode
+// reference: JIS X0510 : 2018 (ISO/IEC 18004 : 2015) Table 7
+func characterCapacity(version int, ecl ErrorCorrectionLevel, mode ModeIndicator) int {
+	bits := dataCodeWords(version, ecl)*8 - modeCharCount - characterCountIndicatorBits(version, mode)
+	if bits < 0 {
+		return 0
+	}
+
+	capacity := 0
+	switch mode {
+	case Numeric:
+		// 3 digits are encoded in 10 bits, 2 digits in 7 bits and 1 digit in 4 bits
+		capacity = bits / 10 * 3
+		if bits%10 >= 7 {
+			capacity += 2
+		} else if bits%10 >= 4 {
+			capacity++
+		}
+	case AlphaNumeric:
+		// 2 characters are encoded in 11 bits and 1 character in 6 bits
+		capacity = bits / 11 * 2
+		if bits%11 >= 6 {
+			capacity++
+		}
+	case EightBits:
+		capacity = bits / 8
+	case Kanji:
+		capacity = bits / 13
+	}
+
+	// character count indicator cannot express the number over its bits
+	if max := 1<<characterCountIndicatorBits(version, mode) - 1; capacity > max {
+		capacity = max
+	}
+	return capacity
+}